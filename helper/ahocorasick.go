@@ -0,0 +1,98 @@
+package helper
+
+import "sort"
+
+// https://en.wikipedia.org/wiki/Aho%E2%80%93Corasick_algorithm
+
+type AhoCorasick struct {
+	Patterns []string
+	nodes    []acNode
+}
+
+type acNode struct {
+	Next map[byte]int
+	Fail int
+	// Output contains the indices of all patterns ending at this node, including those reachable via fail links.
+	Output []int
+}
+
+// PatternMatch describes the occurrence of Patterns[Pattern] at text[Start:End].
+type PatternMatch struct {
+	Pattern    int
+	Start, End int
+}
+
+func NewAhoCorasick(patterns []string) *AhoCorasick {
+	ac := &AhoCorasick{Patterns: patterns, nodes: []acNode{{Next: make(map[byte]int)}}}
+	for i, p := range patterns {
+		if len(p) == 0 {
+			continue
+		}
+		current := 0
+		for j := 0; j < len(p); j++ {
+			next, ok := ac.nodes[current].Next[p[j]]
+			if !ok {
+				next = len(ac.nodes)
+				ac.nodes = append(ac.nodes, acNode{Next: make(map[byte]int)})
+				ac.nodes[current].Next[p[j]] = next
+			}
+			current = next
+		}
+		ac.nodes[current].Output = append(ac.nodes[current].Output, i)
+	}
+
+	// breadth-first over the trie so fail links of shorter prefixes are always known
+	queue := make([]int, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].Next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for b, child := range ac.nodes[current].Next {
+			fail := ac.nodes[current].Fail
+			for {
+				if next, ok := ac.nodes[fail].Next[b]; ok {
+					ac.nodes[child].Fail = next
+					break
+				}
+				if fail == 0 {
+					ac.nodes[child].Fail = 0
+					break
+				}
+				fail = ac.nodes[fail].Fail
+			}
+			ac.nodes[child].Output = append(ac.nodes[child].Output, ac.nodes[ac.nodes[child].Fail].Output...)
+			queue = append(queue, child)
+		}
+	}
+	return ac
+}
+
+// FindAll returns all, possibly overlapping, matches ordered by start position and longest pattern first.
+func (ac *AhoCorasick) FindAll(text string) []PatternMatch {
+	matches := make([]PatternMatch, 0)
+	current := 0
+	for i := 0; i < len(text); i++ {
+		for {
+			if next, ok := ac.nodes[current].Next[text[i]]; ok {
+				current = next
+				break
+			}
+			if current == 0 {
+				break
+			}
+			current = ac.nodes[current].Fail
+		}
+		for _, p := range ac.nodes[current].Output {
+			matches = append(matches, PatternMatch{Pattern: p, Start: i + 1 - len(ac.Patterns[p]), End: i + 1})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+	return matches
+}
@@ -0,0 +1,80 @@
+package helper
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAhoCorasickOverlapping(t *testing.T) {
+	ac := NewAhoCorasick([]string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine"})
+	for _, tc := range []struct {
+		text string
+		want []PatternMatch
+	}{
+		{"oneight", []PatternMatch{{Pattern: 0, Start: 0, End: 3}, {Pattern: 7, Start: 2, End: 7}}},
+		{"twone", []PatternMatch{{Pattern: 1, Start: 0, End: 3}, {Pattern: 0, Start: 2, End: 5}}},
+		{"xtwonexeightwothree", []PatternMatch{
+			{Pattern: 1, Start: 1, End: 4}, {Pattern: 0, Start: 3, End: 6}, {Pattern: 7, Start: 7, End: 12},
+			{Pattern: 1, Start: 11, End: 14}, {Pattern: 2, Start: 14, End: 19},
+		}},
+		{"", []PatternMatch{}},
+		{"abc", []PatternMatch{}},
+	} {
+		if matches := ac.FindAll(tc.text); !reflect.DeepEqual(matches, tc.want) {
+			t.Errorf("%q has matches %v instead of %v", tc.text, matches, tc.want)
+		}
+	}
+}
+
+func TestAhoCorasickSuffixPatterns(t *testing.T) {
+	// "he" is a suffix of "she" and a prefix of "hers", so it is only found via fail links
+	ac := NewAhoCorasick([]string{"he", "she", "hers", "his", ""})
+	want := []PatternMatch{{Pattern: 1, Start: 1, End: 4}, {Pattern: 2, Start: 2, End: 6}, {Pattern: 0, Start: 2, End: 4}}
+	if matches := ac.FindAll("ushers"); !reflect.DeepEqual(matches, want) {
+		t.Errorf("matches are %v instead of %v", matches, want)
+	}
+}
+
+// TestAhoCorasickBruteForce compares all matches with searching every pattern at every position.
+func TestAhoCorasickBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(31))
+	randomString := func(maxLen int) string {
+		b := make([]byte, 1+rnd.Intn(maxLen))
+		for i := range b {
+			b[i] = "ab"[rnd.Intn(2)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 200; i++ {
+		patterns := make([]string, 1+rnd.Intn(5))
+		for j := range patterns {
+			patterns[j] = randomString(4)
+		}
+		text := randomString(20)
+
+		want := make([]PatternMatch, 0)
+		for start := range text {
+			for p, pattern := range patterns {
+				if strings.HasPrefix(text[start:], pattern) {
+					want = append(want, PatternMatch{Pattern: p, Start: start, End: start + len(pattern)})
+				}
+			}
+		}
+		got := NewAhoCorasick(patterns).FindAll(text)
+		// equal patterns are reported in any order
+		for _, matches := range [][]PatternMatch{want, got} {
+			sort.Slice(matches, func(i, j int) bool {
+				if matches[i].Start != matches[j].Start {
+					return matches[i].Start < matches[j].Start
+				}
+				return matches[i].Pattern < matches[j].Pattern
+			})
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("patterns %q in %q have matches %v instead of %v", patterns, text, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package helper
+
+import "math/bits"
+
+// Bitset is a fixed size set of bits.
+type Bitset []uint64
+
+func NewBitset(size int) Bitset {
+	return make(Bitset, (size+63)/64)
+}
+
+func (b Bitset) Get(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b Bitset) Set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b Bitset) Clear(i int) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+func (b Bitset) SetTo(i int, val bool) {
+	if val {
+		b.Set(i)
+	} else {
+		b.Clear(i)
+	}
+}
+
+func (b Bitset) Count() int {
+	var count int
+	for _, w := range b {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// CountCommon returns the number of bits set in both bitsets, which may differ in size.
+func (b Bitset) CountCommon(other Bitset) int {
+	var count int
+	for i := 0; i < len(b) && i < len(other); i++ {
+		count += bits.OnesCount64(b[i] & other[i])
+	}
+	return count
+}
+
+// CountRange returns the number of set bits in [from, to).
+func (b Bitset) CountRange(from, to int) int {
+	var count int
+	b.forRange(from, to, func(word int, mask uint64) {
+		count += bits.OnesCount64(b[word] & mask)
+	})
+	return count
+}
+
+// SetRange sets all bits in [from, to).
+func (b Bitset) SetRange(from, to int) {
+	b.forRange(from, to, func(word int, mask uint64) {
+		b[word] |= mask
+	})
+}
+
+// ClearRange clears all bits in [from, to).
+func (b Bitset) ClearRange(from, to int) {
+	b.forRange(from, to, func(word int, mask uint64) {
+		b[word] &^= mask
+	})
+}
+
+func (b Bitset) forRange(from, to int, f func(word int, mask uint64)) {
+	for from < to {
+		word := from / 64
+		end := Min((word+1)*64, to)
+		mask := (^uint64(0) >> (64 - (end - from))) << (from % 64)
+		f(word, mask)
+		from = end
+	}
+}
+
+// ForEach calls f for every set bit in ascending order.
+func (b Bitset) ForEach(f func(i int)) {
+	for wi, w := range b {
+		for w != 0 {
+			f(wi*64 + bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+}
+
+func (b Bitset) Reset() {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+func (b Bitset) Clone() Bitset {
+	b2 := make(Bitset, len(b))
+	copy(b2, b)
+	return b2
+}
+
+func (b Bitset) Equal(other Bitset) bool {
+	if len(b) != len(other) {
+		return false
+	}
+	for i := range b {
+		if b[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns a FNV-1a hash over all words.
+func (b Bitset) Hash() uint64 {
+	h := uint64(14695981039346656037)
+	for _, w := range b {
+		for i := 0; i < 8; i++ {
+			h ^= (w >> (8 * i)) & 0xff
+			h *= 1099511628211
+		}
+	}
+	return h
+}
@@ -0,0 +1,86 @@
+package helper
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestBitsetRanges compares range operations with a slice of bools, with ranges starting and ending around word boundaries.
+func TestBitsetRanges(t *testing.T) {
+	const size = 200
+	rnd := rand.New(rand.NewSource(1))
+	b := NewBitset(size)
+	model := make([]bool, size)
+	bounds := []int{0, 1, 63, 64, 65, 127, 128, 129, 191, 192, 199, 200}
+
+	for i := 0; i < 2000; i++ {
+		from, to := bounds[rnd.Intn(len(bounds))], bounds[rnd.Intn(len(bounds))]
+		if rnd.Intn(4) == 0 {
+			from, to = rnd.Intn(size+1), rnd.Intn(size+1)
+		}
+		if from > to {
+			from, to = to, from
+		}
+
+		var want int
+		for j := from; j < to; j++ {
+			if model[j] {
+				want++
+			}
+		}
+		if got := b.CountRange(from, to); got != want {
+			t.Fatalf("CountRange(%d, %d) is %d instead of %d", from, to, got, want)
+		}
+
+		set := rnd.Intn(2) == 0
+		if set {
+			b.SetRange(from, to)
+		} else {
+			b.ClearRange(from, to)
+		}
+		for j := from; j < to; j++ {
+			model[j] = set
+		}
+
+		count := 0
+		for j := range model {
+			if b.Get(j) != model[j] {
+				t.Fatalf("bit %d is %v instead of %v after range [%d, %d)", j, b.Get(j), model[j], from, to)
+			}
+			if model[j] {
+				count++
+			}
+		}
+		if b.Count() != count {
+			t.Fatalf("Count is %d instead of %d", b.Count(), count)
+		}
+	}
+}
+
+func TestBitsetCloneEqual(t *testing.T) {
+	b := NewBitset(130)
+	b.SetRange(60, 70)
+	b.Set(129)
+	c := b.Clone()
+	if !c.Equal(b) || c.Hash() != b.Hash() {
+		t.Fatal("clone differs from original")
+	}
+	c.Clear(64)
+	if c.Equal(b) || b.Get(64) != true {
+		t.Fatal("clone shares bits with original")
+	}
+}
+
+func TestBitsetCountCommon(t *testing.T) {
+	b := NewBitset(200)
+	b.SetRange(10, 20)
+	b.Set(150)
+	c := NewBitset(70)
+	c.SetRange(15, 66)
+	if got := b.CountCommon(c); got != 5 {
+		t.Errorf("CountCommon is %d instead of 5", got)
+	}
+	if got := c.CountCommon(b); got != 5 {
+		t.Errorf("CountCommon of smaller bitset is %d instead of 5", got)
+	}
+}
@@ -0,0 +1,165 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CompressedAxis maps sparse coordinates to dense cell indices. Cell i covers the half-open interval [Bounds[i], Bounds[i+1]).
+type CompressedAxis struct {
+	Bounds []int
+}
+
+// NewCompressedAxis creates an axis with cell boundaries at all given values.
+func NewCompressedAxis(values ...int) CompressedAxis {
+	bounds := make([]int, len(values))
+	copy(bounds, values)
+	sort.Ints(bounds)
+	unique := bounds[:0]
+	for i, v := range bounds {
+		if i == 0 || v != bounds[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return CompressedAxis{Bounds: unique}
+}
+
+// Len returns the number of cells.
+func (a CompressedAxis) Len() int {
+	return Max(len(a.Bounds)-1, 0)
+}
+
+// Index returns the cell containing the coordinate v.
+func (a CompressedAxis) Index(v int) (int, bool) {
+	i := sort.SearchInts(a.Bounds, v+1) - 1
+	if i < 0 || i >= a.Len() {
+		return -1, false
+	}
+	return i, true
+}
+
+// Range returns the cells [i, j) exactly covering [from, to). Both values must be boundaries of the axis.
+func (a CompressedAxis) Range(from, to int) (int, int) {
+	i := sort.SearchInts(a.Bounds, from)
+	j := sort.SearchInts(a.Bounds, to)
+	if i >= len(a.Bounds) || a.Bounds[i] != from || j >= len(a.Bounds) || a.Bounds[j] != to {
+		panic(fmt.Sprintf("range [%d, %d) is not aligned to axis boundaries", from, to))
+	}
+	return i, j
+}
+
+// Weight returns the number of coordinates in cell i.
+func (a CompressedAxis) Weight(i int) int64 {
+	return int64(a.Bounds[i+1]) - int64(a.Bounds[i])
+}
+
+// CompressedGrid is an n-dimensional grid over compressed axes. Every cell is weighted by the product of its extents, so the weight of a 2D region is its area and the weight of a 3D region its volume.
+type CompressedGrid struct {
+	Axes []CompressedAxis
+	// Blocked contains all cells with the first axis varying fastest.
+	Blocked []bool
+	strides []int
+}
+
+func NewCompressedGrid(axes ...CompressedAxis) *CompressedGrid {
+	g := &CompressedGrid{Axes: axes, strides: make([]int, len(axes))}
+	size := 1
+	for i, a := range axes {
+		g.strides[i] = size
+		size *= a.Len()
+	}
+	g.Blocked = make([]bool, size)
+	return g
+}
+
+// Cell returns the flat index of the cell with the given index on each axis.
+func (g *CompressedGrid) Cell(indices ...int) int {
+	var cell int
+	for i, index := range indices {
+		cell += index * g.strides[i]
+	}
+	return cell
+}
+
+// Indices returns the index on each axis of a flat cell index.
+func (g *CompressedGrid) Indices(cell int) []int {
+	indices := make([]int, len(g.Axes))
+	for i := len(g.Axes) - 1; i >= 0; i-- {
+		indices[i] = cell / g.strides[i]
+		cell %= g.strides[i]
+	}
+	return indices
+}
+
+func (g *CompressedGrid) Weight(cell int) int64 {
+	weight := int64(1)
+	for i, index := range g.Indices(cell) {
+		weight *= g.Axes[i].Weight(index)
+	}
+	return weight
+}
+
+func (g *CompressedGrid) TotalWeight() int64 {
+	weight := int64(1)
+	for _, a := range g.Axes {
+		weight *= int64(a.Bounds[len(a.Bounds)-1]) - int64(a.Bounds[0])
+	}
+	return weight
+}
+
+// Block marks all cells in the box [from, to) given in uncompressed coordinates as blocked.
+func (g *CompressedGrid) Block(from, to []int) {
+	starts := make([]int, len(g.Axes))
+	ends := make([]int, len(g.Axes))
+	for i, a := range g.Axes {
+		starts[i], ends[i] = a.Range(from[i], to[i])
+		if starts[i] >= ends[i] {
+			return
+		}
+	}
+	indices := make([]int, len(g.Axes))
+	copy(indices, starts)
+	for {
+		g.Blocked[g.Cell(indices...)] = true
+		axis := 0
+		for ; axis < len(indices); axis++ {
+			indices[axis]++
+			if indices[axis] < ends[axis] {
+				break
+			}
+			indices[axis] = starts[axis]
+		}
+		if axis == len(indices) {
+			return
+		}
+	}
+}
+
+// FloodFill fills all unblocked cells connected to the start cell along the axes and returns the filled cells and their total weight.
+func (g *CompressedGrid) FloodFill(start ...int) ([]bool, int64) {
+	filled := make([]bool, len(g.Blocked))
+	startCell := g.Cell(start...)
+	if g.Blocked[startCell] {
+		return filled, 0
+	}
+	filled[startCell] = true
+	var weight int64
+	for stack := []int{startCell}; len(stack) > 0; {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		weight += g.Weight(cell)
+		indices := g.Indices(cell)
+		for i, a := range g.Axes {
+			for _, delta := range []int{-1, 1} {
+				if next := indices[i] + delta; next >= 0 && next < a.Len() {
+					nextCell := cell + delta*g.strides[i]
+					if !filled[nextCell] && !g.Blocked[nextCell] {
+						filled[nextCell] = true
+						stack = append(stack, nextCell)
+					}
+				}
+			}
+		}
+	}
+	return filled, weight
+}
@@ -0,0 +1,102 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompressedAxis(t *testing.T) {
+	a := NewCompressedAxis(20, 5, 0, 5, 2)
+	if !reflect.DeepEqual(a.Bounds, []int{0, 2, 5, 20}) {
+		t.Fatalf("bounds are %v", a.Bounds)
+	}
+	if a.Len() != 3 {
+		t.Errorf("axis has %d instead of 3 cells", a.Len())
+	}
+	for _, tc := range []struct {
+		v, index int
+		ok       bool
+	}{
+		{-1, -1, false}, {0, 0, true}, {1, 0, true}, {2, 1, true}, {4, 1, true}, {5, 2, true}, {19, 2, true}, {20, -1, false},
+	} {
+		if index, ok := a.Index(tc.v); index != tc.index || ok != tc.ok {
+			t.Errorf("coordinate %d is in cell %d (%v) instead of %d (%v)", tc.v, index, ok, tc.index, tc.ok)
+		}
+	}
+	for i, want := range []int64{2, 3, 15} {
+		if w := a.Weight(i); w != want {
+			t.Errorf("cell %d has weight %d instead of %d", i, w, want)
+		}
+	}
+	if i, j := a.Range(2, 20); i != 1 || j != 3 {
+		t.Errorf("range [2, 20) covers cells [%d, %d) instead of [1, 3)", i, j)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("unaligned range does not panic")
+		}
+	}()
+	a.Range(1, 5)
+}
+
+func TestCompressedGridCells(t *testing.T) {
+	g := NewCompressedGrid(NewCompressedAxis(0, 1, 4), NewCompressedAxis(0, 10, 11, 20), NewCompressedAxis(-5, 5))
+	if len(g.Blocked) != 2*3*1 {
+		t.Fatalf("grid has %d instead of 6 cells", len(g.Blocked))
+	}
+	for cell := range g.Blocked {
+		if got := g.Cell(g.Indices(cell)...); got != cell {
+			t.Errorf("cell %d has indices %v, which are cell %d", cell, g.Indices(cell), got)
+		}
+	}
+	if w := g.Weight(g.Cell(1, 2, 0)); w != 3*9*10 {
+		t.Errorf("cell (1, 2, 0) has weight %d instead of %d", w, 3*9*10)
+	}
+	if w := g.TotalWeight(); w != 4*20*10 {
+		t.Errorf("total weight is %d instead of %d", w, 4*20*10)
+	}
+}
+
+func TestCompressedGridFloodFill2D(t *testing.T) {
+	// a square wall [2, 18) with a hole [5, 15) in an area of [0, 20)
+	bounds := []int{0, 2, 5, 15, 18, 20}
+	g := NewCompressedGrid(NewCompressedAxis(bounds...), NewCompressedAxis(bounds...))
+	g.Block([]int{2, 2}, []int{18, 5})
+	g.Block([]int{2, 15}, []int{18, 18})
+	g.Block([]int{2, 5}, []int{5, 15})
+	g.Block([]int{15, 5}, []int{18, 15})
+
+	filled, outside := g.FloodFill(0, 0)
+	if outside != 20*20-16*16 {
+		t.Errorf("outside area is %d instead of %d", outside, 20*20-16*16)
+	}
+	if filled[g.Cell(2, 2)] {
+		t.Errorf("flood fill from the outside reaches the hole")
+	}
+	if _, inside := g.FloodFill(2, 2); inside != 10*10 {
+		t.Errorf("area of the hole is %d instead of %d", inside, 10*10)
+	}
+	if _, wall := g.FloodFill(1, 1); wall != 0 {
+		t.Errorf("flood fill from a blocked cell has area %d", wall)
+	}
+}
+
+func TestCompressedGridFloodFill3D(t *testing.T) {
+	// a hollow cube [1, 9) with walls of thickness 1 in a volume of [0, 10)
+	bounds := []int{0, 1, 2, 8, 9, 10}
+	g := NewCompressedGrid(NewCompressedAxis(bounds...), NewCompressedAxis(bounds...), NewCompressedAxis(bounds...))
+	for axis := 0; axis < 3; axis++ {
+		for _, wall := range [][2]int{{1, 2}, {8, 9}} {
+			from, to := []int{1, 1, 1}, []int{9, 9, 9}
+			from[axis], to[axis] = wall[0], wall[1]
+			g.Block(from, to)
+		}
+	}
+	if _, outside := g.FloodFill(0, 0, 0); outside != 1000-512 {
+		t.Errorf("outside volume is %d instead of %d", outside, 1000-512)
+	}
+	if _, inside := g.FloodFill(2, 2, 2); inside != 216 {
+		t.Errorf("inside volume is %d instead of 216", inside)
+	}
+}
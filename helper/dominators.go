@@ -0,0 +1,126 @@
+package helper
+
+// DominatorTree describes which nodes of a directed graph are unavoidable on all paths from the root:
+// a node d dominates n, if every path from the root to n passes through d.
+type DominatorTree struct {
+	Root int
+	// Idom contains the immediate dominator of every node, -1 for the root and unreachable nodes.
+	Idom     []int
+	Children [][]int
+}
+
+// NewDominatorTree computes the dominator tree of the graph with nodes 0 to n-1 using the iterative algorithm
+// of Cooper, Harvey and Kennedy, which needs a single pass for acyclic graphs.
+func NewDominatorTree(n, root int, successors func(node int) []int) *DominatorTree {
+	// postorder numbering by iterative depth-first search
+	postorder := make([]int, n)
+	for i := range postorder {
+		postorder[i] = -1
+	}
+	visited := make([]bool, n)
+	order := make([]int, 0, n)
+	type frame struct {
+		node, next int
+	}
+	visited[root] = true
+	stack := []frame{{node: root}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		succ := successors(top.node)
+		if top.next < len(succ) {
+			next := succ[top.next]
+			top.next++
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, frame{node: next})
+			}
+			continue
+		}
+		postorder[top.node] = len(order)
+		order = append(order, top.node)
+		stack = stack[:len(stack)-1]
+	}
+
+	predecessors := make([][]int, n)
+	for _, node := range order {
+		for _, next := range successors(node) {
+			predecessors[next] = append(predecessors[next], node)
+		}
+	}
+
+	idom := make([]int, n)
+	for i := range idom {
+		idom[i] = -1
+	}
+	idom[root] = root
+	intersect := func(a, b int) int {
+		for a != b {
+			for postorder[a] < postorder[b] {
+				a = idom[a]
+			}
+			for postorder[b] < postorder[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		// reverse postorder guarantees that at least one predecessor is processed before each node
+		for i := len(order) - 2; i >= 0; i-- {
+			node := order[i]
+			newIdom := -1
+			for _, p := range predecessors[node] {
+				if idom[p] < 0 {
+					continue
+				}
+				if newIdom < 0 {
+					newIdom = p
+				} else {
+					newIdom = intersect(p, newIdom)
+				}
+			}
+			if idom[node] != newIdom {
+				idom[node] = newIdom
+				changed = true
+			}
+		}
+	}
+	idom[root] = -1
+
+	t := &DominatorTree{Root: root, Idom: idom, Children: make([][]int, n)}
+	for node, d := range idom {
+		if d >= 0 {
+			t.Children[d] = append(t.Children[d], node)
+		}
+	}
+	return t
+}
+
+// Dominates returns whether every path from the root to n passes through d.
+func (t *DominatorTree) Dominates(d, n int) bool {
+	for ; n >= 0; n = t.Idom[n] {
+		if n == d {
+			return true
+		}
+	}
+	return false
+}
+
+// SubtreeSizes returns the number of nodes dominated by each node including the node itself.
+func (t *DominatorTree) SubtreeSizes() []int {
+	sizes := make([]int, len(t.Idom))
+	// breadth-first order visits parents before their children, so the reverse order visits children first
+	nodes := []int{t.Root}
+	for i := 0; i < len(nodes); i++ {
+		nodes = append(nodes, t.Children[nodes[i]]...)
+	}
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		sizes[node]++
+		if d := t.Idom[node]; d >= 0 {
+			sizes[d] += sizes[node]
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,148 @@
+package dot
+
+// minimal writer for the Graphviz DOT language, see https://graphviz.org/doc/info/lang.html
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type Attributes map[string]string
+
+type Graph struct {
+	Name      string
+	Directed  bool
+	NodeStyle Attributes
+	EdgeStyle Attributes
+	nodes     []node
+	nodeIndex map[string]int
+	edges     []edge
+}
+
+type node struct {
+	ID    string
+	Attrs Attributes
+}
+
+type edge struct {
+	From, To string
+	Attrs    Attributes
+}
+
+func NewGraph(name string, directed bool) *Graph {
+	return &Graph{
+		Name:      name,
+		Directed:  directed,
+		nodeIndex: make(map[string]int),
+	}
+}
+
+// AddNode adds a node or merges the given attributes into an already existing node.
+func (g *Graph) AddNode(id string, attrs Attributes) {
+	if i, ok := g.nodeIndex[id]; ok {
+		for k, v := range attrs {
+			g.nodes[i].Attrs[k] = v
+		}
+		return
+	}
+	g.nodeIndex[id] = len(g.nodes)
+	merged := make(Attributes, len(attrs))
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	g.nodes = append(g.nodes, node{ID: id, Attrs: merged})
+}
+
+// AddEdge adds an edge and implicitly creates missing nodes without attributes.
+func (g *Graph) AddEdge(from, to string, attrs Attributes) {
+	g.AddNode(from, nil)
+	g.AddNode(to, nil)
+	g.edges = append(g.edges, edge{From: from, To: to, Attrs: attrs})
+}
+
+func (g *Graph) HasNode(id string) bool {
+	_, ok := g.nodeIndex[id]
+	return ok
+}
+
+func (g *Graph) String() string {
+	var sb strings.Builder
+	g.WriteTo(&sb)
+	return sb.String()
+}
+
+func (g *Graph) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	graphType, edgeOp := "graph", " -- "
+	if g.Directed {
+		graphType, edgeOp = "digraph", " -> "
+	}
+	bw.WriteString(graphType + " " + quote(g.Name) + " {\n")
+	if len(g.NodeStyle) > 0 {
+		bw.WriteString("\tnode" + formatAttributes(g.NodeStyle) + ";\n")
+	}
+	if len(g.EdgeStyle) > 0 {
+		bw.WriteString("\tedge" + formatAttributes(g.EdgeStyle) + ";\n")
+	}
+	for _, n := range g.nodes {
+		bw.WriteString("\t" + quote(n.ID) + formatAttributes(n.Attrs) + ";\n")
+	}
+	for _, e := range g.edges {
+		bw.WriteString("\t" + quote(e.From) + edgeOp + quote(e.To) + formatAttributes(e.Attrs) + ";\n")
+	}
+	bw.WriteString("}\n")
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func (g *Graph) WriteFile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := g.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func formatAttributes(attrs Attributes) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + quote(attrs[k])
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote escapes backslashes and double quotes, line breaks are converted to the \n escape sequence of Graphviz.
+func quote(str string) string {
+	return `"` + quoteReplacer.Replace(str) + `"`
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -1,5 +1,10 @@
 package helper
 
+import (
+	"errors"
+	"math/big"
+)
+
 type Integer interface {
 	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
 }
@@ -52,3 +57,43 @@ func Mod[T Integer](d, m T) T {
 	}
 	return res
 }
+
+var (
+	ErrNoCongruenceSolution = errors.New("congruences have no common solution")
+	ErrCongruenceOverflow   = errors.New("solution of congruences exceeds int64")
+)
+
+// SolveCongruences returns the smallest non-negative x with x ≡ residues[i] (mod moduli[i]) for all i
+// together with the combined modulus. Moduli do not need to be coprime.
+// It returns ErrNoCongruenceSolution if the congruences contradict each other and ErrCongruenceOverflow if x or the modulus do not fit into int64.
+func SolveCongruences(residues, moduli []int64) (x int64, modulus int64, err error) {
+	if len(residues) != len(moduli) {
+		panic("residues and moduli must have same length")
+	}
+	r := big.NewInt(0)
+	m := big.NewInt(1)
+	for i := range residues {
+		r2 := big.NewInt(Mod(residues[i], moduli[i]))
+		m2 := big.NewInt(moduli[i])
+
+		// solve r + m*k ≡ r2 (mod m2)
+		g, p := new(big.Int), new(big.Int)
+		g.GCD(p, nil, m, m2)
+		diff := new(big.Int).Sub(r2, r)
+		if new(big.Int).Mod(diff, g).Sign() != 0 {
+			return 0, 0, ErrNoCongruenceSolution
+		}
+		m2g := new(big.Int).Div(m2, g)
+		k := new(big.Int).Div(diff, g)
+		k.Mul(k, p)
+		k.Mod(k, m2g)
+
+		r.Add(r, k.Mul(k, m))
+		m.Mul(m, m2g)
+		r.Mod(r, m)
+	}
+	if !r.IsInt64() || !m.IsInt64() {
+		return 0, 0, ErrCongruenceOverflow
+	}
+	return r.Int64(), m.Int64(), nil
+}
@@ -0,0 +1,108 @@
+package helper
+
+// OrderedHashMap distributes keys into a fixed number of buckets using a pluggable hash function.
+// Entries keep their insertion order within a bucket, updating the value of a key does not change its position.
+// Lookups, upserts and deletions are O(1), iteration is deterministic by bucket and insertion order.
+type OrderedHashMap[K comparable, V any] struct {
+	hash    func(K) int
+	buckets []orderedHashMapBucket[K, V]
+	entries map[K]*orderedHashMapEntry[K, V]
+}
+
+type orderedHashMapBucket[K comparable, V any] struct {
+	First, Last *orderedHashMapEntry[K, V]
+	Len         int
+}
+
+type orderedHashMapEntry[K comparable, V any] struct {
+	Key        K
+	Value      V
+	Bucket     int
+	Prev, Next *orderedHashMapEntry[K, V]
+}
+
+func NewOrderedHashMap[K comparable, V any](bucketCount int, hash func(K) int) *OrderedHashMap[K, V] {
+	return &OrderedHashMap[K, V]{
+		hash:    hash,
+		buckets: make([]orderedHashMapBucket[K, V], bucketCount),
+		entries: make(map[K]*orderedHashMapEntry[K, V]),
+	}
+}
+
+func (m *OrderedHashMap[K, V]) Len() int {
+	return len(m.entries)
+}
+
+func (m *OrderedHashMap[K, V]) BucketCount() int {
+	return len(m.buckets)
+}
+
+func (m *OrderedHashMap[K, V]) BucketLen(bucket int) int {
+	return m.buckets[bucket].Len
+}
+
+func (m *OrderedHashMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.entries[key]; ok {
+		return e.Value, true
+	}
+	var empty V
+	return empty, false
+}
+
+// Put updates the value of an existing key or appends a new entry to the end of its bucket.
+func (m *OrderedHashMap[K, V]) Put(key K, val V) {
+	if e, ok := m.entries[key]; ok {
+		e.Value = val
+		return
+	}
+	bucketIndex := Mod(m.hash(key), len(m.buckets))
+	b := &m.buckets[bucketIndex]
+	e := &orderedHashMapEntry[K, V]{Key: key, Value: val, Bucket: bucketIndex, Prev: b.Last}
+	if b.Last != nil {
+		b.Last.Next = e
+	} else {
+		b.First = e
+	}
+	b.Last = e
+	b.Len++
+	m.entries[key] = e
+}
+
+// Delete removes a key and returns whether it was present.
+func (m *OrderedHashMap[K, V]) Delete(key K) bool {
+	e, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	b := &m.buckets[e.Bucket]
+	if e.Prev != nil {
+		e.Prev.Next = e.Next
+	} else {
+		b.First = e.Next
+	}
+	if e.Next != nil {
+		e.Next.Prev = e.Prev
+	} else {
+		b.Last = e.Prev
+	}
+	b.Len--
+	delete(m.entries, key)
+	return true
+}
+
+// Range calls f for all entries ordered by bucket and position within the bucket.
+func (m *OrderedHashMap[K, V]) Range(f func(bucket, slot int, key K, val V)) {
+	for i := range m.buckets {
+		m.RangeBucket(i, func(slot int, key K, val V) {
+			f(i, slot, key, val)
+		})
+	}
+}
+
+func (m *OrderedHashMap[K, V]) RangeBucket(bucket int, f func(slot int, key K, val V)) {
+	slot := 0
+	for e := m.buckets[bucket].First; e != nil; e = e.Next {
+		f(slot, e.Key, e.Value)
+		slot++
+	}
+}
@@ -0,0 +1,103 @@
+package helper
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type orderedHashMapEntryValues struct {
+	Bucket, Slot int
+	Key          string
+	Value        int
+}
+
+func orderedHashMapContent(m *OrderedHashMap[string, int]) []orderedHashMapEntryValues {
+	entries := make([]orderedHashMapEntryValues, 0)
+	m.Range(func(bucket, slot int, key string, val int) {
+		entries = append(entries, orderedHashMapEntryValues{Bucket: bucket, Slot: slot, Key: key, Value: val})
+	})
+	return entries
+}
+
+func TestOrderedHashMapOrder(t *testing.T) {
+	// all keys share a bucket except "x"
+	m := NewOrderedHashMap[string, int](2, func(key string) int {
+		if key == "x" {
+			return 1
+		}
+		return 0
+	})
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("x", 3)
+	m.Put("c", 4)
+
+	// updating keeps the position
+	m.Put("a", 10)
+	want := []orderedHashMapEntryValues{{0, 0, "a", 10}, {0, 1, "b", 2}, {0, 2, "c", 4}, {1, 0, "x", 3}}
+	if content := orderedHashMapContent(m); !reflect.DeepEqual(content, want) {
+		t.Errorf("map contains %v instead of %v after update", content, want)
+	}
+
+	// deleting and inserting again appends to the end of the bucket
+	if !m.Delete("a") {
+		t.Errorf("existing key is not deleted")
+	}
+	if m.Delete("a") {
+		t.Errorf("deleted key is deleted again")
+	}
+	m.Put("a", 5)
+	want = []orderedHashMapEntryValues{{0, 0, "b", 2}, {0, 1, "c", 4}, {0, 2, "a", 5}, {1, 0, "x", 3}}
+	if content := orderedHashMapContent(m); !reflect.DeepEqual(content, want) {
+		t.Errorf("map contains %v instead of %v after delete and re-insert", content, want)
+	}
+
+	// deleting the first, middle and last entries keeps the links intact
+	m.Delete("c")
+	m.Delete("x")
+	m.Put("d", 6)
+	m.Delete("b")
+	want = []orderedHashMapEntryValues{{0, 0, "a", 5}, {0, 1, "d", 6}}
+	if content := orderedHashMapContent(m); !reflect.DeepEqual(content, want) {
+		t.Errorf("map contains %v instead of %v after deletes", content, want)
+	}
+	if m.Len() != 2 || m.BucketLen(0) != 2 || m.BucketLen(1) != 0 {
+		t.Errorf("map has %d entries and buckets of length %d and %d", m.Len(), m.BucketLen(0), m.BucketLen(1))
+	}
+	if val, ok := m.Get("d"); !ok || val != 6 {
+		t.Errorf("d is %d (%v) instead of 6", val, ok)
+	}
+	if _, ok := m.Get("b"); ok {
+		t.Errorf("deleted key is still present")
+	}
+}
+
+func TestOrderedHashMapFocusingPower(t *testing.T) {
+	// HASHMAP procedure of day 15
+	hash := func(str string) int {
+		var h int
+		for _, r := range str {
+			h = (h + int(r)) * 17 % 256
+		}
+		return h
+	}
+	m := NewOrderedHashMap[string, int](256, hash)
+	for _, step := range strings.Split("rn=1,cm-,qp=3,cm=2,qp-,pc=4,ot=9,ab=5,pc-,pc=6,ot=7", ",") {
+		if label, found := strings.CutSuffix(step, "-"); found {
+			m.Delete(label)
+		} else {
+			label, focalLength, _ := strings.Cut(step, "=")
+			val, _ := strconv.Atoi(focalLength)
+			m.Put(label, val)
+		}
+	}
+	var power int
+	m.Range(func(bucket, slot int, _ string, focalLength int) {
+		power += (bucket + 1) * (slot + 1) * focalLength
+	})
+	if power != 145 {
+		t.Errorf("focusing power is %d instead of 145", power)
+	}
+}
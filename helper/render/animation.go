@@ -0,0 +1,120 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Animation struct {
+	Frames []*Canvas
+	Delay  time.Duration
+	// Every only keeps every n-th frame passed to Add.
+	Every    int
+	added    int
+	last     func() *Canvas
+	lastKept bool
+}
+
+func NewAnimation(delay time.Duration) *Animation {
+	return &Animation{Delay: delay, Every: 1}
+}
+
+func (a *Animation) Add(c *Canvas) {
+	a.AddFunc(func() *Canvas { return c })
+}
+
+// AddFunc is like Add, but only calls frame for frames that are kept, so skipped frames are never built.
+// Finish may call the frame func of the last skipped frame, so the state it draws must not change until then.
+func (a *Animation) AddFunc(frame func() *Canvas) {
+	a.last = frame
+	a.lastKept = a.Every <= 1 || a.added%a.Every == 0
+	if a.lastKept {
+		a.Frames = append(a.Frames, frame())
+	}
+	a.added++
+}
+
+// Finish keeps the last frame passed to Add regardless of Every.
+func (a *Animation) Finish() {
+	if a.last != nil && !a.lastKept {
+		a.Frames = append(a.Frames, a.last())
+		a.lastKept = true
+	}
+}
+
+// Play renders all frames in place on an ANSI terminal.
+func (a *Animation) Play(w io.Writer) {
+	// clear screen once, then only move the cursor to the top left corner to avoid flickering
+	fmt.Fprint(w, "\033[2J")
+	for i, f := range a.Frames {
+		fmt.Fprintf(w, "\033[H%s\n[frame %d/%d]\n", f.ANSI(), i+1, len(a.Frames))
+		time.Sleep(a.Delay)
+	}
+}
+
+func (a *Animation) WriteGIF(file string, cellSize int) error {
+	palette := a.palette()
+	anim := &gif.GIF{}
+	delay := int(a.Delay / (10 * time.Millisecond))
+	for _, f := range a.Frames {
+		anim.Image = append(anim.Image, f.Image(cellSize, palette))
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(out, anim); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// WritePNGs writes every frame as numbered PNG file into dir.
+func (a *Animation) WritePNGs(dir string, cellSize int) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	palette := a.palette()
+	for i, f := range a.Frames {
+		if err := writePNG(filepath.Join(dir, fmt.Sprintf("frame-%05d.png", i)), f.Image(cellSize, palette)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePNG(file string, img image.Image) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, img); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (a *Animation) palette() color.Palette {
+	palette := color.Palette{}
+	known := map[color.Color]bool{}
+	for _, f := range a.Frames {
+		for _, c := range f.Palette() {
+			if !known[c] {
+				known[c] = true
+				palette = append(palette, c)
+			}
+		}
+	}
+	return palette
+}
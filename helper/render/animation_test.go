@@ -0,0 +1,27 @@
+package render
+
+import "testing"
+
+func TestAddFuncSkipsFrames(t *testing.T) {
+	a := NewAnimation(0)
+	a.Every = 3
+	built := 0
+	for i := 0; i < 8; i++ {
+		a.AddFunc(func() *Canvas {
+			built++
+			return NewCanvas([][]rune{{'.'}})
+		})
+	}
+	// frames 0, 3 and 6 are kept
+	if built != 3 || len(a.Frames) != 3 {
+		t.Errorf("built %d canvases for %d frames instead of 3", built, len(a.Frames))
+	}
+	a.Finish()
+	if built != 4 || len(a.Frames) != 4 {
+		t.Errorf("after finish, built %d canvases for %d frames instead of 4", built, len(a.Frames))
+	}
+	a.Finish()
+	if built != 4 || len(a.Frames) != 4 {
+		t.Errorf("second finish built %d canvases for %d frames instead of 4", built, len(a.Frames))
+	}
+}
@@ -0,0 +1,231 @@
+package render
+
+// renders rune grids with coloured overlays to the terminal (ANSI escape codes) or to images
+
+import (
+	"aoc/helper"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+type Color struct {
+	ANSI string
+	RGB  color.RGBA
+}
+
+var (
+	Red     = Color{ANSI: "31", RGB: color.RGBA{R: 220, G: 50, B: 47, A: 255}}
+	Green   = Color{ANSI: "32", RGB: color.RGBA{R: 80, G: 200, B: 60, A: 255}}
+	Yellow  = Color{ANSI: "33", RGB: color.RGBA{R: 240, G: 200, B: 0, A: 255}}
+	Blue    = Color{ANSI: "34", RGB: color.RGBA{R: 40, G: 110, B: 230, A: 255}}
+	Magenta = Color{ANSI: "35", RGB: color.RGBA{R: 210, G: 60, B: 200, A: 255}}
+	Cyan    = Color{ANSI: "36", RGB: color.RGBA{R: 40, G: 200, B: 220, A: 255}}
+	White   = Color{ANSI: "97", RGB: color.RGBA{R: 255, G: 255, B: 255, A: 255}}
+	Gray    = Color{ANSI: "90", RGB: color.RGBA{R: 110, G: 110, B: 110, A: 255}}
+
+	colorBackground = color.RGBA{A: 255}
+	colorBase       = color.RGBA{R: 60, G: 60, B: 60, A: 255}
+)
+
+// RGB returns a true color, which requires a terminal with 24-bit color support.
+func RGB(r, g, b uint8) Color {
+	return Color{ANSI: fmt.Sprintf("38;2;%d;%d;%d", r, g, b), RGB: color.RGBA{R: r, G: g, B: b, A: 255}}
+}
+
+// Layer marks cells of a canvas. Marked cells are drawn in the layer color and with the layer symbol, if it is non-zero.
+type Layer struct {
+	Name   string
+	Color  Color
+	Symbol rune
+	Cells  map[helper.Point2D[int]]bool
+}
+
+type Canvas struct {
+	Width, Height int
+	Tiles         [][]rune
+	Layers        []*Layer
+}
+
+func NewCanvas(tiles [][]rune) *Canvas {
+	c := &Canvas{Height: len(tiles), Tiles: make([][]rune, len(tiles))}
+	for y := range tiles {
+		c.Tiles[y] = make([]rune, len(tiles[y]))
+		copy(c.Tiles[y], tiles[y])
+		c.Width = helper.Max(c.Width, len(tiles[y]))
+	}
+	return c
+}
+
+func NewCanvasFunc(width, height int, f func(x, y int) rune) *Canvas {
+	tiles := make([][]rune, height)
+	for y := range tiles {
+		tiles[y] = make([]rune, width)
+		for x := range tiles[y] {
+			tiles[y][x] = f(x, y)
+		}
+	}
+	return &Canvas{Width: width, Height: height, Tiles: tiles}
+}
+
+// Overlay adds a new layer on top of all existing layers.
+func (c *Canvas) Overlay(name string, col Color, symbol rune, points []helper.Point2D[int]) *Layer {
+	l := &Layer{Name: name, Color: col, Symbol: symbol, Cells: make(map[helper.Point2D[int]]bool, len(points))}
+	for _, p := range points {
+		l.Cells[p] = true
+	}
+	c.Layers = append(c.Layers, l)
+	return l
+}
+
+// OverlayFunc adds a new layer containing all cells for which f returns true.
+func (c *Canvas) OverlayFunc(name string, col Color, symbol rune, f func(x, y int) bool) *Layer {
+	points := make([]helper.Point2D[int], 0)
+	for y := range c.Tiles {
+		for x := range c.Tiles[y] {
+			if f(x, y) {
+				points = append(points, helper.Point2D[int]{X: x, Y: y})
+			}
+		}
+	}
+	return c.Overlay(name, col, symbol, points)
+}
+
+// Cell returns the rune and topmost layer of a cell, the layer is nil for cells without overlay.
+func (c *Canvas) Cell(x, y int) (rune, *Layer) {
+	r := c.Tiles[y][x]
+	p := helper.Point2D[int]{X: x, Y: y}
+	for i := len(c.Layers) - 1; i >= 0; i-- {
+		if l := c.Layers[i]; l.Cells[p] {
+			if l.Symbol != 0 {
+				r = l.Symbol
+			}
+			return r, l
+		}
+	}
+	return r, nil
+}
+
+// topLayers returns the topmost layer of every cell, which is faster than calling Cell for canvases with many layers.
+func (c *Canvas) topLayers() [][]*Layer {
+	layers := make([][]*Layer, len(c.Tiles))
+	for y := range c.Tiles {
+		layers[y] = make([]*Layer, len(c.Tiles[y]))
+	}
+	for _, l := range c.Layers {
+		for p := range l.Cells {
+			if p.Y >= 0 && p.Y < len(layers) && p.X >= 0 && p.X < len(layers[p.Y]) {
+				layers[p.Y][p.X] = l
+			}
+		}
+	}
+	return layers
+}
+
+func cellRune(tile rune, l *Layer) rune {
+	if l != nil && l.Symbol != 0 {
+		return l.Symbol
+	}
+	return tile
+}
+
+// String returns the canvas with overlay symbols but without colors.
+func (c *Canvas) String() string {
+	layers := c.topLayers()
+	lines := make([]string, c.Height)
+	for y := range c.Tiles {
+		var sb strings.Builder
+		for x := range c.Tiles[y] {
+			sb.WriteRune(cellRune(c.Tiles[y][x], layers[y][x]))
+		}
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ANSI returns the canvas with overlays colored by ANSI escape codes.
+func (c *Canvas) ANSI() string {
+	layers := c.topLayers()
+	lines := make([]string, c.Height)
+	for y := range c.Tiles {
+		var sb strings.Builder
+		var current *Layer
+		for x := range c.Tiles[y] {
+			l := layers[y][x]
+			if l != current {
+				if l == nil {
+					sb.WriteString("\033[0m")
+				} else {
+					sb.WriteString("\033[" + l.Color.ANSI + "m")
+				}
+				current = l
+			}
+			sb.WriteRune(cellRune(c.Tiles[y][x], l))
+		}
+		if current != nil {
+			sb.WriteString("\033[0m")
+		}
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Image draws every cell as square of cellSize pixels: overlays in their color, other non-empty tiles in gray.
+// The palette can hold at most 256 colors, use RGBA for canvases with more colors.
+func (c *Canvas) Image(cellSize int, palette color.Palette) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, c.Width*cellSize, c.Height*cellSize), palette)
+	indices := map[color.Color]uint8{}
+	c.draw(cellSize, func(x, y int, col color.Color) {
+		index, ok := indices[col]
+		if !ok {
+			index = uint8(palette.Index(col))
+			indices[col] = index
+		}
+		img.SetColorIndex(x, y, index)
+	})
+	return img
+}
+
+// RGBA draws the canvas like Image, but without color limitations.
+func (c *Canvas) RGBA(cellSize int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, c.Width*cellSize, c.Height*cellSize))
+	c.draw(cellSize, img.Set)
+	return img
+}
+
+func (c *Canvas) WritePNG(file string, cellSize int) error {
+	return writePNG(file, c.RGBA(cellSize))
+}
+
+func (c *Canvas) draw(cellSize int, set func(x, y int, col color.Color)) {
+	layers := c.topLayers()
+	for y := range c.Tiles {
+		for x := range c.Tiles[y] {
+			var col color.Color = colorBackground
+			if l := layers[y][x]; l != nil {
+				col = l.Color.RGB
+			} else if r := c.Tiles[y][x]; r != '.' && r != ' ' {
+				col = colorBase
+			}
+			for py := y * cellSize; py < (y+1)*cellSize; py++ {
+				for px := x * cellSize; px < (x+1)*cellSize; px++ {
+					set(px, py, col)
+				}
+			}
+		}
+	}
+}
+
+// Palette returns the colors used by all layers of the canvas and the base colors.
+func (c *Canvas) Palette() color.Palette {
+	palette := color.Palette{colorBackground, colorBase}
+	known := map[color.RGBA]bool{colorBackground: true, colorBase: true}
+	for _, l := range c.Layers {
+		if !known[l.Color.RGB] {
+			known[l.Color.RGB] = true
+			palette = append(palette, l.Color.RGB)
+		}
+	}
+	return palette
+}
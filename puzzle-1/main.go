@@ -4,17 +4,23 @@ package main
 
 import (
 	"aoc/helper"
+	"flag"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 func main() {
+	wordsFile := flag.String("words", "", "load spelled-out numbers for part 2 from file (one word=value per line)")
+	flag.Parse()
+
 	lines := helper.ReadLines("input.txt")
 
-	calibrationValues1 := getCalibrationValues(lines, map[string]int{"1": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9})
+	digitMappings := map[string]int{"1": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9}
+	calibrationValues1 := getCalibrationValues(lines, digitMappings)
 	solution1 := sumAllCalibrationValues(calibrationValues1)
 
-	calibrationValues2 := getCalibrationValues(lines, map[string]int{"1": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9,
+	wordMappings := map[string]int{
 		"one":   1,
 		"two":   2,
 		"three": 3,
@@ -24,20 +30,52 @@ func main() {
 		"seven": 7,
 		"eight": 8,
 		"nine":  9,
-	})
+	}
+	if len(*wordsFile) > 0 {
+		wordMappings = readTokenMappings(*wordsFile)
+	}
+	for t, v := range digitMappings {
+		wordMappings[t] = v
+	}
+	calibrationValues2 := getCalibrationValues(lines, wordMappings)
 	solution2 := sumAllCalibrationValues(calibrationValues2)
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
 }
 
+func readTokenMappings(file string) map[string]int {
+	tokenMappings := make(map[string]int)
+	for i, line := range helper.ReadNonEmptyLines(file) {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := helper.SplitAndTrim(line, "=")
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			helper.ExitWithMessage("malformed token mapping %q in line %d", line, i+1)
+		}
+		val, err := strconv.Atoi(parts[1])
+		helper.ExitOnError(err, "invalid value in line %d", i+1)
+		tokenMappings[parts[0]] = val
+	}
+	return tokenMappings
+}
+
 func getCalibrationValues(lines []string, tokenMappings map[string]int) []int {
+	tokens := make([]string, 0, len(tokenMappings))
+	values := make([]int, 0, len(tokenMappings))
+	helper.IterateMapInKeyOrder(tokenMappings, func(t string, v int) {
+		tokens = append(tokens, t)
+		values = append(values, v)
+	})
+	matcher := helper.NewAhoCorasick(tokens)
+
 	calibrationValues := make([]int, 0, len(lines))
 	for _, line := range lines {
 		if len(line) > 0 {
-			tokens := tokenize(line, tokenMappings)
-			if len(tokens) > 0 {
-				val := 10*tokens[0] + tokens[len(tokens)-1]
+			matches := matcher.FindAll(line)
+			if len(matches) > 0 {
+				val := 10*values[matches[0].Pattern] + values[lastMatchByStart(matches).Pattern]
 				calibrationValues = append(calibrationValues, val)
 			}
 		}
@@ -45,19 +83,13 @@ func getCalibrationValues(lines []string, tokenMappings map[string]int) []int {
 	return calibrationValues
 }
 
-func tokenize(line string, tokenMappings map[string]int) []int {
-	runes := []rune(line)
-	tokens := make([]int, 0)
-	for i := 0; i < len(runes); i++ {
-		remainder := string(runes[i:])
-		for t, v := range tokenMappings {
-			if strings.HasPrefix(remainder, t) {
-				tokens = append(tokens, v)
-				break
-			}
-		}
+// lastMatchByStart returns the longest of all matches with the highest start position.
+func lastMatchByStart(matches []helper.PatternMatch) helper.PatternMatch {
+	last := len(matches) - 1
+	for last > 0 && matches[last-1].Start == matches[last].Start {
+		last--
 	}
-	return tokens
+	return matches[last]
 }
 
 func sumAllCalibrationValues(calibrationValues []int) int {
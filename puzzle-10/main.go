@@ -4,19 +4,29 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/render"
+	"flag"
 	"fmt"
 	"math"
-	"strings"
 	"sync"
 	"sync/atomic"
 )
 
 func main() {
+	showWorld := flag.Bool("show", false, "print loop and enclosed tiles")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	world := ParseWorld(lines)
 	solution1 := world.FindMaxPathToAnimal()
-	solution2 := world.CountEmptyFieldsWithNonZeroWindingNumber()
+	solution2, err := world.CountEnclosedTiles()
+	helper.ExitOnError(err, "count enclosed tiles")
+	if *showWorld {
+		fmt.Println(world.Canvas().ANSI())
+		fmt.Println("animal sits on", string(world.Tiles[world.Animal.Y][world.Animal.X].Rune))
+		fmt.Println("dangling pipes:", world.FindDanglingPipes())
+	}
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
@@ -26,6 +36,30 @@ type Point struct {
 	X, Y int
 }
 
+func (p Point) Add(p2 Point) Point {
+	return Point{X: p.X + p2.X, Y: p.Y + p2.Y}
+}
+
+func (p Point) Neg() Point {
+	return Point{X: -p.X, Y: -p.Y}
+}
+
+var (
+	dirNorth = Point{X: 0, Y: -1}
+	dirSouth = Point{X: 0, Y: 1}
+	dirWest  = Point{X: -1, Y: 0}
+	dirEast  = Point{X: 1, Y: 0}
+
+	pipeDirs = map[rune][2]Point{
+		'|': {dirNorth, dirSouth},
+		'-': {dirWest, dirEast},
+		'L': {dirNorth, dirEast},
+		'J': {dirNorth, dirWest},
+		'7': {dirSouth, dirWest},
+		'F': {dirSouth, dirEast},
+	}
+)
+
 type World struct {
 	Width, Height int
 	Tiles         [][]Tile
@@ -40,16 +74,21 @@ type Tile struct {
 }
 
 func (t Tile) ConnectsToWest() bool {
-	return t.Rune == 'S' || t.Rune == '-' || t.Rune == 'J' || t.Rune == '7'
+	return t.ConnectsTo(dirWest)
 }
 func (t Tile) ConnectsToNorth() bool {
-	return t.Rune == 'S' || t.Rune == '|' || t.Rune == 'L' || t.Rune == 'J'
+	return t.ConnectsTo(dirNorth)
 }
 func (t Tile) ConnectsToEast() bool {
-	return t.Rune == 'S' || t.Rune == '-' || t.Rune == 'L' || t.Rune == 'F'
+	return t.ConnectsTo(dirEast)
 }
 func (t Tile) ConnectsToSouth() bool {
-	return t.Rune == 'S' || t.Rune == '|' || t.Rune == '7' || t.Rune == 'F'
+	return t.ConnectsTo(dirSouth)
+}
+
+func (t Tile) ConnectsTo(dir Point) bool {
+	dirs, ok := pipeDirs[t.Rune]
+	return ok && (dirs[0] == dir || dirs[1] == dir)
 }
 
 func ParseWorld(lines []string) World {
@@ -72,9 +111,91 @@ func ParseWorld(lines []string) World {
 	}
 	world.Height = len(world.Tiles)
 	world.Width = len(world.Tiles[0])
+
+	pipe, err := world.InferAnimalPipe()
+	helper.ExitOnError(err, "infer pipe below animal")
+	world.Tiles[world.Animal.Y][world.Animal.X].Rune = pipe
 	return world
 }
 
+// InferAnimalPipe returns the only pipe shape below the animal that closes a loop through the animal.
+func (w *World) InferAnimalPipe() (rune, error) {
+	animalTile := &w.Tiles[w.Animal.Y][w.Animal.X]
+	originalRune := animalTile.Rune
+	defer func() { animalTile.Rune = originalRune }()
+
+	candidates := make([]rune, 0)
+	for _, pipe := range "|-LJ7F" {
+		animalTile.Rune = pipe
+		if _, err := w.traceLoop(); err == nil {
+			candidates = append(candidates, pipe)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, fmt.Errorf("no closed loop passes through the animal at %v", w.Animal)
+	}
+	if len(candidates) > 1 {
+		return 0, fmt.Errorf("ambiguous pipe below animal at %v, possible shapes are %q", w.Animal, string(candidates))
+	}
+	return candidates[0], nil
+}
+
+func (w *World) InBounds(p Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < w.Width && p.Y < w.Height
+}
+
+// traceLoop follows the pipes starting at the animal and returns all tiles of the loop in walking order.
+func (w *World) traceLoop() ([]Point, error) {
+	dirs, ok := pipeDirs[w.Tiles[w.Animal.Y][w.Animal.X].Rune]
+	if !ok {
+		return nil, fmt.Errorf("no pipe below animal at %v", w.Animal)
+	}
+	loop := []Point{w.Animal}
+	pos, dir := w.Animal, dirs[0]
+	for {
+		next := pos.Add(dir)
+		if !w.InBounds(next) || !w.Tiles[next.Y][next.X].ConnectsTo(dir.Neg()) {
+			return nil, fmt.Errorf("loop through animal is open at %v", pos)
+		}
+		if next == w.Animal {
+			return loop, nil
+		}
+		if len(loop) > w.Width*w.Height {
+			return nil, fmt.Errorf("loop through animal does not return")
+		}
+		loop = append(loop, next)
+
+		nextDirs := pipeDirs[w.Tiles[next.Y][next.X].Rune]
+		if nextDirs[0] == dir.Neg() {
+			dir = nextDirs[1]
+		} else {
+			dir = nextDirs[0]
+		}
+		pos = next
+	}
+}
+
+// FindDanglingPipes returns all pipes with at least one end that is not connected to a matching pipe.
+func (w *World) FindDanglingPipes() []Point {
+	dangling := make([]Point, 0)
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			dirs, ok := pipeDirs[w.Tiles[y][x].Rune]
+			if !ok {
+				continue
+			}
+			for _, dir := range dirs {
+				next := Point{X: x, Y: y}.Add(dir)
+				if !w.InBounds(next) || !w.Tiles[next.Y][next.X].ConnectsTo(dir.Neg()) {
+					dangling = append(dangling, Point{X: x, Y: y})
+					break
+				}
+			}
+		}
+	}
+	return dangling
+}
+
 func (w *World) FindMaxPathToAnimal() int {
 	nextVisit := []Point{w.Animal}
 	w.Tiles[w.Animal.Y][w.Animal.X].StepsToAnimal = 0
@@ -134,87 +255,64 @@ func (w *World) CanMoveSouth(x, y int) bool {
 	return y < (w.Height-1) && w.Tiles[y][x].ConnectsToSouth() && w.Tiles[y+1][x].ConnectsToNorth()
 }
 
-func (w *World) ExtractLoop() []Point {
-	if w.Tiles[w.Animal.Y][w.Animal.X].StepsToAnimal != 0 {
-		helper.ExitWithMessage("use World.FindMaxPathToAnimal before World.ExtractLoop")
+// ExtractLoop returns all tiles of the loop through the animal and marks them as part of the loop.
+func (w *World) ExtractLoop() ([]Point, error) {
+	loop, err := w.traceLoop()
+	if err != nil {
+		return nil, err
 	}
-
-	visited := map[Point]bool{}
-
-	loop := []Point{w.Animal}
-	for {
-		t := loop[len(loop)-1]
-		w.Tiles[t.Y][t.X].PartOfLoop = true
-		visited[t] = true
-
-		west := Point{t.X - 1, t.Y}
-		if w.CanMoveWest(t.X, t.Y) && w.Tiles[west.Y][west.X].StepsToAnimal >= 0 {
-			if len(loop) > 2 && west == w.Animal {
-				break
-			}
-			if !visited[west] {
-				loop = append(loop, west)
-				continue
-			}
-		}
-
-		north := Point{t.X, t.Y - 1}
-		if w.CanMoveNorth(t.X, t.Y) && w.Tiles[north.Y][north.X].StepsToAnimal >= 0 {
-			if len(loop) > 2 && north == w.Animal {
-				break
-			}
-			if !visited[north] {
-				loop = append(loop, north)
-				continue
-			}
-		}
-
-		east := Point{t.X + 1, t.Y}
-		if w.CanMoveEast(t.X, t.Y) && w.Tiles[east.Y][east.X].StepsToAnimal >= 0 {
-			if len(loop) > 2 && east == w.Animal {
-				break
-			}
-			if !visited[east] {
-				loop = append(loop, east)
-				continue
-			}
-		}
-
-		south := Point{t.X, t.Y + 1}
-		if w.CanMoveSouth(t.X, t.Y) && w.Tiles[south.Y][south.X].StepsToAnimal >= 0 {
-			if len(loop) > 2 && south == w.Animal {
-				break
-			}
-			if !visited[south] {
-				loop = append(loop, south)
-				continue
-			}
-		}
+	for _, p := range loop {
+		w.Tiles[p.Y][p.X].PartOfLoop = true
 	}
-	return loop
+	return loop, nil
 }
 
-func (w *World) String() string {
-	lines := []string{}
+// CountEnclosedTiles counts tiles inside the loop using scanline parity: every loop tile with a north connection toggles inside.
+func (w *World) CountEnclosedTiles() (int, error) {
+	if _, err := w.ExtractLoop(); err != nil {
+		return 0, err
+	}
+	var count int
 	for y := 0; y < w.Height; y++ {
-		var line string
+		inside := false
 		for x := 0; x < w.Width; x++ {
-			t := w.Tiles[y][x]
-			if t.Enclosed {
-				line += "I"
-			} else if t.PartOfLoop {
-				line += string(t.Rune)
-			} else {
-				line += "."
+			t := &w.Tiles[y][x]
+			if t.PartOfLoop {
+				if t.ConnectsToNorth() {
+					inside = !inside
+				}
+			} else if inside {
+				t.Enclosed = true
+				count++
 			}
 		}
-		lines = append(lines, line)
 	}
-	return strings.Join(lines, "\n")
+	return count, nil
+}
+
+func (w *World) String() string {
+	return w.Canvas().String()
 }
 
-func (w *World) CountEmptyFieldsWithNonZeroWindingNumber() int {
-	loop := w.ExtractLoop()
+// Canvas returns the loop and enclosed tiles as overlays, all other tiles are hidden.
+func (w *World) Canvas() *render.Canvas {
+	canvas := render.NewCanvasFunc(w.Width, w.Height, func(x, y int) rune {
+		if w.Tiles[y][x].PartOfLoop {
+			return w.Tiles[y][x].Rune
+		}
+		return '.'
+	})
+	canvas.OverlayFunc("loop", render.Blue, 0, func(x, y int) bool { return w.Tiles[y][x].PartOfLoop })
+	canvas.OverlayFunc("enclosed", render.Green, 'I', func(x, y int) bool { return w.Tiles[y][x].Enclosed })
+	canvas.Overlay("animal", render.Red, 0, []helper.Point2D[int]{{X: w.Animal.X, Y: w.Animal.Y}})
+	return canvas
+}
+
+func (w *World) CountEmptyFieldsWithNonZeroWindingNumber() (int, error) {
+	loop, err := w.ExtractLoop()
+	if err != nil {
+		return 0, err
+	}
 	candidates := make([]Point, 0)
 	for y := 0; y < w.Height; y++ {
 		for x := 0; x < w.Width; x++ {
@@ -239,7 +337,7 @@ func (w *World) CountEmptyFieldsWithNonZeroWindingNumber() int {
 		}(p)
 	}
 	wg.Wait()
-	return int(count)
+	return int(count), nil
 }
 
 func ComputeWindingNumber(p Point, loop []Point) float64 {
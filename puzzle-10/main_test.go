@@ -0,0 +1,103 @@
+package main
+
+import (
+	"aoc/helper"
+	"reflect"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	for _, tc := range []struct {
+		file       string
+		pipe       rune
+		loopLength int
+		enclosed   int
+		dangling   int
+	}{
+		{"example-1.txt", 'F', 8, 1, 0},
+		{"example-2.txt", 'F', 16, 1, 0},
+		{"example-3.txt", 'F', 44, 4, 0},
+		{"example-4.txt", 'F', 140, 8, 0},
+		{"example-5.txt", '7', 160, 10, 35},
+	} {
+		world := ParseWorld(helper.ReadNonEmptyLines(tc.file))
+		if pipe := world.Tiles[world.Animal.Y][world.Animal.X].Rune; pipe != tc.pipe {
+			t.Errorf("%s: animal sits on %q instead of %q", tc.file, pipe, tc.pipe)
+		}
+		loop, err := world.ExtractLoop()
+		if err != nil {
+			t.Errorf("%s: %s", tc.file, err.Error())
+			continue
+		}
+		if len(loop) != tc.loopLength {
+			t.Errorf("%s: loop has length %d instead of %d", tc.file, len(loop), tc.loopLength)
+		}
+		if steps := world.FindMaxPathToAnimal(); steps != tc.loopLength/2 {
+			t.Errorf("%s: farthest tile is %d instead of %d steps away", tc.file, steps, tc.loopLength/2)
+		}
+		enclosed, err := world.CountEnclosedTiles()
+		if err != nil {
+			t.Errorf("%s: %s", tc.file, err.Error())
+		} else if enclosed != tc.enclosed {
+			t.Errorf("%s: %d instead of %d tiles are enclosed", tc.file, enclosed, tc.enclosed)
+		}
+		if dangling := world.FindDanglingPipes(); len(dangling) != tc.dangling {
+			t.Errorf("%s: %d instead of %d pipes are dangling", tc.file, len(dangling), tc.dangling)
+		}
+	}
+}
+
+// TestEnclosedTilesWindingNumber compares the scanline parity with the winding number of every tile.
+func TestEnclosedTilesWindingNumber(t *testing.T) {
+	for _, file := range []string{"example-1.txt", "example-2.txt", "example-3.txt", "example-4.txt", "example-5.txt", "input.txt"} {
+		world := ParseWorld(helper.ReadNonEmptyLines(file))
+		enclosed, err := world.CountEnclosedTiles()
+		if err != nil {
+			t.Fatalf("%s: %s", file, err.Error())
+		}
+		winding, err := world.CountEmptyFieldsWithNonZeroWindingNumber()
+		if err != nil {
+			t.Fatalf("%s: %s", file, err.Error())
+		}
+		if enclosed != winding {
+			t.Errorf("%s: scanline parity found %d enclosed tiles, but winding number found %d", file, enclosed, winding)
+		}
+	}
+}
+
+func TestDanglingPipes(t *testing.T) {
+	world := ParseWorld([]string{
+		".....",
+		".S-7.",
+		".|.|-",
+		".L-J.",
+		"..|..",
+	})
+	want := []Point{{X: 4, Y: 2}, {X: 2, Y: 4}}
+	if dangling := world.FindDanglingPipes(); !reflect.DeepEqual(dangling, want) {
+		t.Errorf("dangling pipes are %v instead of %v", dangling, want)
+	}
+}
+
+func TestInferAnimalPipeErrors(t *testing.T) {
+	for _, lines := range [][]string{
+		// open loop
+		{".....", ".S-7.", ".|.|.", ".L-..", "....."},
+		// two loops through the animal
+		{"F7.", "LS7", ".LJ"},
+	} {
+		world := World{Width: len(lines[0]), Height: len(lines)}
+		for y, line := range lines {
+			world.Tiles = append(world.Tiles, make([]Tile, len(line)))
+			for x, r := range line {
+				world.Tiles[y][x] = Tile{Rune: r}
+				if r == 'S' {
+					world.Animal = Point{X: x, Y: y}
+				}
+			}
+		}
+		if pipe, err := world.InferAnimalPipe(); err == nil {
+			t.Errorf("%v: animal sits on %q", lines, pipe)
+		}
+	}
+}
@@ -4,24 +4,33 @@ package main
 
 import (
 	"aoc/helper"
+	"flag"
 	"fmt"
+	"math/big"
+	"sort"
 	"strings"
 )
 
 func main() {
-	lines := helper.ReadNonEmptyLines("input.txt")
-
-	universe1 := ParseUniverse(lines)
-	universe2 := universe1.Clone()
+	factorStr := flag.String("factor", "", "additionally print the path pair sum for the given expansion factor")
+	flag.Parse()
 
-	universe1.Expand(1)
-	solution1 := universe1.GetAllShortestPathPairSum()
+	lines := helper.ReadNonEmptyLines("input.txt")
 
-	universe2.Expand(999999)
-	solution2 := universe2.GetAllShortestPathPairSum()
+	universe := ParseUniverse(lines)
+	solution1 := universe.GetExpandedShortestPathPairSum(big.NewInt(2))
+	solution2 := universe.GetExpandedShortestPathPairSum(big.NewInt(1000000))
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
+
+	if len(*factorStr) > 0 {
+		factor, ok := new(big.Int).SetString(*factorStr, 10)
+		if !ok || factor.Sign() <= 0 {
+			helper.ExitWithMessage("invalid expansion factor %q", *factorStr)
+		}
+		fmt.Println("factor", factor, "->", universe.GetExpandedShortestPathPairSum(factor))
+	}
 }
 
 type Universe struct {
@@ -71,80 +80,6 @@ func (u *Universe) String() string {
 	return strings.Join(linesStr, "\n")
 }
 
-func (u *Universe) Clone() *Universe {
-	galaxies := make([]Galaxy, len(u.Galaxies))
-	copy(galaxies, u.Galaxies)
-	return &Universe{
-		MaxX:     u.MaxX,
-		MaxY:     u.MaxY,
-		Galaxies: galaxies,
-	}
-}
-
-func (u *Universe) Expand(size int) {
-	for y := 0; y <= u.MaxY; y++ {
-		if u.IsEmptyRow(y) {
-			u.ExpandRow(y, size)
-			y += size
-		}
-	}
-	for x := 0; x <= u.MaxX; x++ {
-		if u.IsEmptyCol(x) {
-			u.ExpandCol(x, size)
-			x += size
-		}
-	}
-}
-
-func (u *Universe) IsEmptyRow(y int) bool {
-	for _, g := range u.Galaxies {
-		if g.Y == y {
-			return false
-		}
-	}
-	return true
-}
-
-func (u *Universe) ExpandRow(y, size int) bool {
-	u.MaxY += size
-	for i := range u.Galaxies {
-		if u.Galaxies[i].Y > y {
-			u.Galaxies[i].Y += size
-		}
-	}
-	return true
-}
-
-func (u *Universe) IsEmptyCol(x int) bool {
-	for _, g := range u.Galaxies {
-		if g.X == x {
-			return false
-		}
-	}
-	return true
-}
-
-func (u *Universe) ExpandCol(x, size int) bool {
-	u.MaxX += size
-	for i := range u.Galaxies {
-		if u.Galaxies[i].X > x {
-			u.Galaxies[i].X += size
-		}
-	}
-	return true
-}
-
-func (u *Universe) GetAllShortestPathPairSum() int {
-	var sum int
-	for i := range u.Galaxies {
-		for j := i + 1; j < len(u.Galaxies); j++ {
-			length := u.ShortestPathLengthFromTo(u.Galaxies[i], u.Galaxies[j])
-			sum += length
-		}
-	}
-	return sum
-}
-
 func (u *Universe) ShortestPathLengthFromTo(g1, g2 Galaxy) int {
 	dx := g2.X - g1.X
 	if dx < 0 {
@@ -156,3 +91,43 @@ func (u *Universe) ShortestPathLengthFromTo(g1, g2 Galaxy) int {
 	}
 	return dx + dy
 }
+
+// GetExpandedShortestPathPairSum returns the sum of all pairwise distances after replacing every empty row and column
+// by factor empty rows and columns without modifying the universe.
+func (u *Universe) GetExpandedShortestPathPairSum(factor *big.Int) *big.Int {
+	xs := make([]int, len(u.Galaxies))
+	ys := make([]int, len(u.Galaxies))
+	for i, g := range u.Galaxies {
+		xs[i], ys[i] = g.X, g.Y
+	}
+	baseX, emptyX := AxisDistanceSums(xs)
+	baseY, emptyY := AxisDistanceSums(ys)
+
+	// every coordinate c becomes c + emptyBefore(c)*(factor-1), which keeps the order of coordinates
+	sum := new(big.Int).Add(big.NewInt(emptyX), big.NewInt(emptyY))
+	sum.Mul(sum, new(big.Int).Sub(factor, big.NewInt(1)))
+	sum.Add(sum, big.NewInt(baseX))
+	return sum.Add(sum, big.NewInt(baseY))
+}
+
+// AxisDistanceSums returns the sum of pairwise distances of all coordinates and the sum of pairwise differences
+// of the number of empty lines before each coordinate in O(n log n).
+func AxisDistanceSums(coords []int) (int64, int64) {
+	sorted := make([]int, len(coords))
+	copy(sorted, coords)
+	sort.Ints(sorted)
+
+	// for sorted values the sum of |a_i - a_j| over all pairs is the sum of a_i * (2i - n + 1)
+	var baseSum, emptySum int64
+	var emptyBefore int64
+	n := int64(len(sorted))
+	for i, c := range sorted {
+		if i > 0 && c > sorted[i-1] {
+			emptyBefore += int64(c - sorted[i-1] - 1)
+		}
+		weight := 2*int64(i) - n + 1
+		baseSum += int64(c) * weight
+		emptySum += emptyBefore * weight
+	}
+	return baseSum, emptySum
+}
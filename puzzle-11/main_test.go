@@ -0,0 +1,64 @@
+package main
+
+import (
+	"aoc/helper"
+	"math/big"
+	"testing"
+)
+
+// bruteForcePairSum moves every galaxy by factor-1 for every empty row and column before it and sums up all pairwise distances.
+func bruteForcePairSum(u Universe, factor int) int {
+	emptyX := make([]bool, u.MaxX+1)
+	emptyY := make([]bool, u.MaxY+1)
+	for i := range emptyX {
+		emptyX[i] = true
+	}
+	for i := range emptyY {
+		emptyY[i] = true
+	}
+	for _, g := range u.Galaxies {
+		emptyX[g.X] = false
+		emptyY[g.Y] = false
+	}
+	expanded := make([]Galaxy, len(u.Galaxies))
+	for i, g := range u.Galaxies {
+		expanded[i] = g
+		for x := 0; x < g.X; x++ {
+			if emptyX[x] {
+				expanded[i].X += factor - 1
+			}
+		}
+		for y := 0; y < g.Y; y++ {
+			if emptyY[y] {
+				expanded[i].Y += factor - 1
+			}
+		}
+	}
+
+	var sum int
+	for i := range expanded {
+		for j := i + 1; j < len(expanded); j++ {
+			sum += u.ShortestPathLengthFromTo(expanded[i], expanded[j])
+		}
+	}
+	return sum
+}
+
+func TestExpandedPairSum(t *testing.T) {
+	example := ParseUniverse(helper.ReadNonEmptyLines("example-1.txt"))
+	for factor, want := range map[int]int64{2: 374, 10: 1030, 100: 8410} {
+		if sum := example.GetExpandedShortestPathPairSum(big.NewInt(int64(factor))); sum.Int64() != want {
+			t.Errorf("example with factor %d: sum is %s instead of %d", factor, sum, want)
+		}
+	}
+
+	for _, file := range []string{"example-1.txt", "input.txt"} {
+		universe := ParseUniverse(helper.ReadNonEmptyLines(file))
+		for _, factor := range []int{1, 2, 10, 100} {
+			want := bruteForcePairSum(universe, factor)
+			if sum := universe.GetExpandedShortestPathPairSum(big.NewInt(int64(factor))); sum.Int64() != int64(want) {
+				t.Errorf("%s with factor %d: sum is %s instead of %d", file, factor, sum, want)
+			}
+		}
+	}
+}
@@ -5,25 +5,28 @@ package main
 import (
 	"aoc/helper"
 	"fmt"
+	"math/bits"
 )
 
 func main() {
 	lines := helper.ReadLines("input.txt")
 
 	patterns := ParsePatterns(lines)
-	solution1 := SummarizeReflectionsPart1(patterns)
-	solution2 := SummarizeReflectionsPart2(patterns)
+	solution1 := SummarizeReflections(patterns, 0)
+	solution2 := SummarizeReflections(patterns, 1)
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
 }
 
+// Pattern stores rows and columns as bitmasks, where bit i of a row is set if the tile in column i is a rock and vice versa.
 type Pattern struct {
-	Rows []string
-	Cols []string
+	Width, Height int
+	Rows          []uint64
+	Cols          []uint64
 }
 
 func ParsePatterns(lines []string) []Pattern {
-	patterns := make([]Pattern, 0)
+	patternLines := make([][]string, 0)
 	requireNewPattern := true
 	for _, line := range lines {
 		if len(line) == 0 {
@@ -31,100 +34,137 @@ func ParsePatterns(lines []string) []Pattern {
 
 		} else {
 			if requireNewPattern {
-				patterns = append(patterns, Pattern{})
+				patternLines = append(patternLines, []string{})
 				requireNewPattern = false
 			}
-			patterns[len(patterns)-1].Rows = append(patterns[len(patterns)-1].Rows, line)
+			patternLines[len(patternLines)-1] = append(patternLines[len(patternLines)-1], line)
 		}
 	}
-	for i := range patterns {
-		patterns[i].computeCols()
+	patterns := make([]Pattern, len(patternLines))
+	for i := range patternLines {
+		patterns[i] = ParsePattern(patternLines[i])
 	}
 	return patterns
 }
 
-func (p *Pattern) computeCols() {
-	p.Cols = make([]string, len(p.Rows[0]))
-	for y := range p.Rows {
-		for x, r := range p.Rows[y] {
-			p.Cols[x] += string(r)
+func ParsePattern(lines []string) Pattern {
+	p := Pattern{Width: len(lines[0]), Height: len(lines)}
+	if p.Width > 64 || p.Height > 64 {
+		helper.ExitWithMessage("pattern of size %dx%d exceeds 64 bits", p.Width, p.Height)
+	}
+	p.Rows = make([]uint64, p.Height)
+	p.Cols = make([]uint64, p.Width)
+	for y := range lines {
+		if len(lines[y]) != p.Width {
+			helper.ExitWithMessage("mismatching line length in pattern line %d", y+1)
+		}
+		for x, r := range lines[y] {
+			if r == '#' {
+				p.Rows[y] |= 1 << x
+				p.Cols[x] |= 1 << y
+			}
 		}
 	}
+	return p
 }
 
-func findReflection(arr []string) int {
-	for i := 0; i < len(arr)-1; i++ {
-		if countReflectionSmudges(arr, i) == 0 {
-			return i
-		}
+// Axis describes a mirror between Pos and Pos+1, which is horizontal when mirroring rows.
+type Axis struct {
+	Horizontal bool
+	Pos        int
+}
+
+// FindReflection returns all axes for which exactly the given number of tiles differ from their mirrored tiles.
+func (p Pattern) FindReflection(smudges int) []Axis {
+	axes := make([]Axis, 0)
+	for _, pos := range findReflections(p.Rows, smudges) {
+		axes = append(axes, Axis{Horizontal: true, Pos: pos})
 	}
-	return -1
+	for _, pos := range findReflections(p.Cols, smudges) {
+		axes = append(axes, Axis{Horizontal: false, Pos: pos})
+	}
+	return axes
 }
 
-func findReflectionWithSingleSmudge(arr []string) int {
-	for i := 0; i < len(arr)-1; i++ {
-		if countReflectionSmudges(arr, i) == 1 {
-			return i
+func findReflections(lines []uint64, smudges int) []int {
+	positions := make([]int, 0)
+	for i := 0; i < len(lines)-1; i++ {
+		if countReflectionSmudges(lines, i, smudges) == smudges {
+			positions = append(positions, i)
 		}
 	}
-	return -1
+	return positions
 }
 
-func countReflectionSmudges(arr []string, pos int) int {
-	checkSize := pos
-	if checkSize > (len(arr) - pos - 2) {
-		checkSize = len(arr) - pos - 2
-	}
+// countReflectionSmudges stops counting as soon as more than maxSmudges differences are found.
+func countReflectionSmudges(lines []uint64, pos int, maxSmudges int) int {
 	var count int
-	for i := 0; i <= checkSize; i++ {
-		for j := range arr[pos-i] {
-			if arr[pos-i][j] != arr[pos+i+1][j] {
-				count++
-			}
+	for i := 0; pos-i >= 0 && pos+i+1 < len(lines); i++ {
+		count += bits.OnesCount64(lines[pos-i] ^ lines[pos+i+1])
+		if count > maxSmudges {
+			break
 		}
 	}
 	return count
 }
 
-func SummarizeReflectionsPart1(patterns []Pattern) int {
-	var sum int
-	for _, p := range patterns {
-		reflectionRow := findReflection(p.Rows)
-		reflectionCol := findReflection(p.Cols)
-		if reflectionRow >= 0 && reflectionCol >= 0 {
-			helper.ExitWithMessage("both row and col reflection detected")
-		}
-		if reflectionRow < 0 && reflectionCol < 0 {
-			helper.ExitWithMessage("no reflection detected")
-		}
+// RotationDifferences returns the number of tiles that differ after rotating the pattern clockwise by the given quarter turns.
+// Only square patterns can be compared for odd quarter turns.
+func (p Pattern) RotationDifferences(quarterTurns int) (int, bool) {
+	switch helper.Mod(quarterTurns, 4) {
+	case 0:
+		return 0, true
+	case 2:
+		return p.countDifferences(func(y int) uint64 { return reverseBits(p.Rows[p.Height-1-y], p.Width) }), true
+	}
+	if p.Width != p.Height {
+		return 0, false
+	}
+	if helper.Mod(quarterTurns, 4) == 1 {
+		return p.countDifferences(func(y int) uint64 { return reverseBits(p.Cols[y], p.Height) }), true
+	}
+	return p.countDifferences(func(y int) uint64 { return p.Cols[p.Width-1-y] }), true
+}
 
-		if reflectionCol >= 0 {
-			sum += reflectionCol + 1
-		}
-		if reflectionRow >= 0 {
-			sum += 100 * (reflectionRow + 1)
-		}
+// DiagonalDifferences returns the number of tiles that differ after mirroring a square pattern
+// at the main diagonal or the anti diagonal.
+func (p Pattern) DiagonalDifferences(anti bool) (int, bool) {
+	if p.Width != p.Height {
+		return 0, false
 	}
-	return sum
+	if anti {
+		return p.countDifferences(func(y int) uint64 { return reverseBits(p.Cols[p.Width-1-y], p.Height) }), true
+	}
+	return p.countDifferences(func(y int) uint64 { return p.Cols[y] }), true
+}
+
+func (p Pattern) countDifferences(transformedRow func(y int) uint64) int {
+	var count int
+	for y := range p.Rows {
+		count += bits.OnesCount64(p.Rows[y] ^ transformedRow(y))
+	}
+	return count
 }
 
-func SummarizeReflectionsPart2(patterns []Pattern) int {
+func reverseBits(v uint64, width int) uint64 {
+	return bits.Reverse64(v) >> (64 - width)
+}
+
+func SummarizeReflections(patterns []Pattern, smudges int) int {
 	var sum int
 	for _, p := range patterns {
-		reflectionRow := findReflectionWithSingleSmudge(p.Rows)
-		reflectionCol := findReflectionWithSingleSmudge(p.Cols)
-		if reflectionRow >= 0 && reflectionCol >= 0 {
-			helper.ExitWithMessage("both row and col reflection detected")
+		axes := p.FindReflection(smudges)
+		if len(axes) > 1 {
+			helper.ExitWithMessage("multiple reflections detected: %v", axes)
 		}
-		if reflectionRow < 0 && reflectionCol < 0 {
+		if len(axes) == 0 {
 			helper.ExitWithMessage("no reflection detected")
 		}
 
-		if reflectionCol >= 0 {
-			sum += reflectionCol + 1
-		}
-		if reflectionRow >= 0 {
-			sum += 100 * (reflectionRow + 1)
+		if axes[0].Horizontal {
+			sum += 100 * (axes[0].Pos + 1)
+		} else {
+			sum += axes[0].Pos + 1
 		}
 	}
 	return sum
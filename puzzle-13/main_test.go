@@ -0,0 +1,120 @@
+package main
+
+import (
+	"aoc/helper"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	patterns := ParsePatterns(helper.ReadLines("example-1.txt"))
+	if sum := SummarizeReflections(patterns, 0); sum != 405 {
+		t.Errorf("part 1 is %d instead of 405", sum)
+	}
+	if sum := SummarizeReflections(patterns, 1); sum != 400 {
+		t.Errorf("part 2 is %d instead of 400", sum)
+	}
+
+	for _, tc := range []struct {
+		pattern, smudges int
+		want             []Axis
+	}{
+		{0, 0, []Axis{{Horizontal: false, Pos: 4}}},
+		{1, 0, []Axis{{Horizontal: true, Pos: 3}}},
+		{0, 1, []Axis{{Horizontal: true, Pos: 2}}},
+		{1, 1, []Axis{{Horizontal: true, Pos: 0}}},
+	} {
+		if axes := patterns[tc.pattern].FindReflection(tc.smudges); !reflect.DeepEqual(axes, tc.want) {
+			t.Errorf("pattern %d with %d smudges reflects at %v instead of %v", tc.pattern, tc.smudges, axes, tc.want)
+		}
+	}
+}
+
+func TestSymmetries(t *testing.T) {
+	for _, tc := range []struct {
+		lines []string
+		// differences after 0 to 3 clockwise quarter turns
+		rotations [4]int
+		diagonal  int
+		anti      int
+	}{
+		{[]string{"#.#", "...", "#.#"}, [4]int{0, 0, 0, 0}, 0, 0},
+		{[]string{".#.", "###", ".#."}, [4]int{0, 0, 0, 0}, 0, 0},
+		{[]string{"#..", "...", "..#"}, [4]int{0, 4, 0, 4}, 0, 0},
+		{[]string{"##.", "#..", "..."}, [4]int{0, 4, 6, 4}, 0, 6},
+		{[]string{"#...", "...#", "#...", "...#"}, [4]int{0, 8, 0, 8}, 4, 4},
+	} {
+		p := ParsePattern(tc.lines)
+		for turns, want := range tc.rotations {
+			if diff, ok := p.RotationDifferences(turns); !ok || diff != want {
+				t.Errorf("%v rotated by %d quarter turns has %d (%v) instead of %d differences", tc.lines, turns, diff, ok, want)
+			}
+		}
+		if diff, ok := p.DiagonalDifferences(false); !ok || diff != tc.diagonal {
+			t.Errorf("%v mirrored at the diagonal has %d (%v) instead of %d differences", tc.lines, diff, ok, tc.diagonal)
+		}
+		if diff, ok := p.DiagonalDifferences(true); !ok || diff != tc.anti {
+			t.Errorf("%v mirrored at the anti diagonal has %d (%v) instead of %d differences", tc.lines, diff, ok, tc.anti)
+		}
+	}
+
+	// half turns work for every shape, but quarter turns and diagonals only for squares
+	p := ParsePattern([]string{"#..", "..#"})
+	if diff, ok := p.RotationDifferences(2); !ok || diff != 0 {
+		t.Errorf("half turn of a rectangle has %d (%v) differences", diff, ok)
+	}
+	if _, ok := p.RotationDifferences(1); ok {
+		t.Errorf("quarter turn of a rectangle is accepted")
+	}
+	if _, ok := p.DiagonalDifferences(false); ok {
+		t.Errorf("diagonal of a rectangle is accepted")
+	}
+}
+
+// TestSymmetriesBruteForce compares the bitmask transformations with transforming the tiles one by one.
+func TestSymmetriesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(13))
+	for i := 0; i < 100; i++ {
+		n := 1 + rnd.Intn(8)
+		lines := make([]string, n)
+		for y := range lines {
+			line := make([]byte, n)
+			for x := range line {
+				line[x] = ".#"[rnd.Intn(2)]
+			}
+			lines[y] = string(line)
+		}
+		p := ParsePattern(lines)
+
+		countDifferences := func(transform func(x, y int) (int, int)) int {
+			var count int
+			for y := 0; y < n; y++ {
+				for x := 0; x < n; x++ {
+					tx, ty := transform(x, y)
+					if lines[y][x] != lines[ty][tx] {
+						count++
+					}
+				}
+			}
+			return count
+		}
+		rotations := []func(x, y int) (int, int){
+			func(x, y int) (int, int) { return x, y },
+			func(x, y int) (int, int) { return y, n - 1 - x },
+			func(x, y int) (int, int) { return n - 1 - x, n - 1 - y },
+			func(x, y int) (int, int) { return n - 1 - y, x },
+		}
+		for turns, rotate := range rotations {
+			if diff, _ := p.RotationDifferences(turns); diff != countDifferences(rotate) {
+				t.Errorf("%v rotated by %d quarter turns has %d instead of %d differences", lines, turns, diff, countDifferences(rotate))
+			}
+		}
+		if diff, _ := p.DiagonalDifferences(false); diff != countDifferences(func(x, y int) (int, int) { return y, x }) {
+			t.Errorf("%v mirrored at the diagonal has wrong difference %d", lines, diff)
+		}
+		if diff, _ := p.DiagonalDifferences(true); diff != countDifferences(func(x, y int) (int, int) { return n - 1 - y, n - 1 - x }) {
+			t.Errorf("%v mirrored at the anti diagonal has wrong difference %d", lines, diff)
+		}
+	}
+}
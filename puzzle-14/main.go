@@ -4,22 +4,45 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/render"
+	"flag"
 	"fmt"
 	"hash/fnv"
+	"os"
 	"strings"
 	"time"
 )
 
 func main() {
+	animateCycles := flag.Int("animate", 0, "animate the given number of tilt cycles in the terminal")
+	gifFile := flag.String("gif", "", "write animated tilt cycles as GIF to file")
+	pngDir := flag.String("png", "", "write animated tilt cycles as PNG sequence to directory")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	panel := ParsePanel(lines)
-	panel2 := panel.Clone()
-	panel.TiltNorth()
-	solution1 := panel.ComputeNorthWeight()
+	if *animateCycles > 0 || len(*gifFile) > 0 || len(*pngDir) > 0 {
+		anim := render.NewAnimation(200 * time.Millisecond)
+		animPanel := panel.Clone()
+		animPanel.AnimateTiltCycles(helper.Max(*animateCycles, 1), anim)
+		if *animateCycles > 0 {
+			anim.Play(os.Stdout)
+		}
+		if len(*gifFile) > 0 {
+			helper.ExitOnError(anim.WriteGIF(*gifFile, 4), "write gif")
+		}
+		if len(*pngDir) > 0 {
+			helper.ExitOnError(anim.WritePNGs(*pngDir, 4), "write png sequence")
+		}
+	}
+	bitPanel := NewBitPanel(panel)
+	bitPanel.TiltNorth()
+	solution1 := bitPanel.ComputeNorthWeight()
 
-	panel2.TiltCycles(1000000000)
-	solution2 := panel2.ComputeNorthWeight()
+	bitPanel2 := NewBitPanel(panel)
+	bitPanel2.TiltCycles(1000000000)
+	solution2 := bitPanel2.ComputeNorthWeight()
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
@@ -91,6 +114,25 @@ func (p *Panel) TiltCycles(count int) {
 	}
 }
 
+// AnimateTiltCycles performs tilt cycles and adds a frame to the animation after every single tilt.
+func (p *Panel) AnimateTiltCycles(count int, anim *render.Animation) {
+	anim.Add(p.Canvas())
+	for i := 0; i < count; i++ {
+		for _, tilt := range []func(){p.TiltNorth, p.TiltWest, p.TiltSouth, p.TiltEast} {
+			tilt()
+			anim.Add(p.Canvas())
+		}
+	}
+	anim.Finish()
+}
+
+func (p *Panel) Canvas() *render.Canvas {
+	canvas := render.NewCanvas(p.Rows)
+	canvas.OverlayFunc("rolling", render.Yellow, 0, func(x, y int) bool { return p.Rows[y][x] == 'O' })
+	canvas.OverlayFunc("fixed", render.Gray, 0, func(x, y int) bool { return p.Rows[y][x] == '#' })
+	return canvas
+}
+
 func (p *Panel) Hash() uint32 {
 	h := fnv.New32a()
 	for y := range p.Rows {
@@ -194,3 +236,141 @@ func (p *Panel) ComputeNorthWeight() int {
 	}
 	return weight
 }
+
+// BitPanel stores rolling and fixed rocks as bitsets in row-major order.
+// Tilts move all rolling rocks of a segment between fixed rocks at once.
+type BitPanel struct {
+	Width, Height int
+	Rolling       helper.Bitset
+	Fixed         helper.Bitset
+	// rowSegments contain ranges [from, to) of free cells in row-major order, colSegments in column-major order.
+	rowSegments []Segment
+	colSegments []Segment
+	transposed  helper.Bitset
+}
+
+type Segment struct {
+	From, To int
+}
+
+func NewBitPanel(p Panel) *BitPanel {
+	bp := &BitPanel{
+		Width:      p.Width,
+		Height:     p.Height,
+		Rolling:    helper.NewBitset(p.Width * p.Height),
+		Fixed:      helper.NewBitset(p.Width * p.Height),
+		transposed: helper.NewBitset(p.Width * p.Height),
+	}
+	for y := range p.Rows {
+		for x, r := range p.Rows[y] {
+			switch r {
+			case 'O':
+				bp.Rolling.Set(y*p.Width + x)
+			case '#':
+				bp.Fixed.Set(y*p.Width + x)
+			}
+		}
+	}
+	bp.rowSegments = bp.findSegments(p.Height, p.Width, func(line, i int) int { return line*p.Width + i })
+	bp.colSegments = bp.findSegments(p.Width, p.Height, func(line, i int) int { return i*p.Width + line })
+	return bp
+}
+
+// findSegments returns the ranges between fixed rocks of all lines, where cell maps a line position to the row-major index.
+func (bp *BitPanel) findSegments(lineCount, lineLength int, cell func(line, i int) int) []Segment {
+	segments := make([]Segment, 0)
+	for line := 0; line < lineCount; line++ {
+		start := 0
+		for i := 0; i <= lineLength; i++ {
+			if i == lineLength || bp.Fixed.Get(cell(line, i)) {
+				if i > start {
+					segments = append(segments, Segment{From: line*lineLength + start, To: line*lineLength + i})
+				}
+				start = i + 1
+			}
+		}
+	}
+	return segments
+}
+
+func (bp *BitPanel) Clone() *BitPanel {
+	bp2 := *bp
+	bp2.Rolling = bp.Rolling.Clone()
+	bp2.transposed = helper.NewBitset(bp.Width * bp.Height)
+	return &bp2
+}
+
+func (bp *BitPanel) TiltNorth() {
+	bp.tiltColumns(false)
+}
+
+func (bp *BitPanel) TiltSouth() {
+	bp.tiltColumns(true)
+}
+
+func (bp *BitPanel) TiltWest() {
+	tiltSegments(bp.Rolling, bp.rowSegments, false)
+}
+
+func (bp *BitPanel) TiltEast() {
+	tiltSegments(bp.Rolling, bp.rowSegments, true)
+}
+
+func (bp *BitPanel) tiltColumns(toEnd bool) {
+	bp.transpose(bp.Rolling, bp.transposed, bp.Width, bp.Height)
+	tiltSegments(bp.transposed, bp.colSegments, toEnd)
+	bp.transpose(bp.transposed, bp.Rolling, bp.Height, bp.Width)
+}
+
+// transpose copies src with lines of the given length into dst with lines of length lineCount.
+func (bp *BitPanel) transpose(src, dst helper.Bitset, lineLength, lineCount int) {
+	dst.Reset()
+	src.ForEach(func(i int) {
+		dst.Set((i%lineLength)*lineCount + i/lineLength)
+	})
+}
+
+func tiltSegments(rolling helper.Bitset, segments []Segment, toEnd bool) {
+	for _, s := range segments {
+		count := rolling.CountRange(s.From, s.To)
+		if count == 0 || count == s.To-s.From {
+			continue
+		}
+		rolling.ClearRange(s.From, s.To)
+		if toEnd {
+			rolling.SetRange(s.To-count, s.To)
+		} else {
+			rolling.SetRange(s.From, s.From+count)
+		}
+	}
+}
+
+func (bp *BitPanel) TiltCycles(count int) {
+	knownIterations := make(map[uint64][]int)
+	knownConfigs := make([]helper.Bitset, 0)
+	for i := 0; i < count; i++ {
+		hash := bp.Rolling.Hash()
+		for _, knownIndex := range knownIterations[hash] {
+			if knownConfigs[knownIndex].Equal(bp.Rolling) {
+				dstIndex := knownIndex + (count-knownIndex)%(i-knownIndex)
+				copy(bp.Rolling, knownConfigs[dstIndex])
+				return
+			}
+		}
+		knownIterations[hash] = append(knownIterations[hash], i)
+		knownConfigs = append(knownConfigs, bp.Rolling.Clone())
+
+		bp.TiltNorth()
+		bp.TiltWest()
+		bp.TiltSouth()
+		bp.TiltEast()
+	}
+}
+
+func (bp *BitPanel) ComputeNorthWeight() int {
+	var weight int
+	bp.Rolling.ForEach(func(i int) {
+		weight += bp.Height - i/bp.Width
+	})
+	return weight
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"aoc/helper"
+	"strings"
+	"testing"
+)
+
+// bitPanelString renders the bit panel like Panel.String.
+func bitPanelString(bp *BitPanel) string {
+	lines := make([]string, bp.Height)
+	for y := range lines {
+		var sb strings.Builder
+		for x := 0; x < bp.Width; x++ {
+			switch i := y*bp.Width + x; {
+			case bp.Rolling.Get(i):
+				sb.WriteRune('O')
+			case bp.Fixed.Get(i):
+				sb.WriteRune('#')
+			default:
+				sb.WriteRune('.')
+			}
+		}
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestBitPanelTilts(t *testing.T) {
+	panel := ParsePanel(helper.ReadNonEmptyLines("example-1.txt"))
+	bitPanel := NewBitPanel(panel)
+	for i, tilt := range []struct {
+		name  string
+		panel func()
+		bits  func()
+	}{
+		{"north", panel.TiltNorth, bitPanel.TiltNorth},
+		{"west", panel.TiltWest, bitPanel.TiltWest},
+		{"south", panel.TiltSouth, bitPanel.TiltSouth},
+		{"east", panel.TiltEast, bitPanel.TiltEast},
+		{"west", panel.TiltWest, bitPanel.TiltWest},
+		{"north", panel.TiltNorth, bitPanel.TiltNorth},
+	} {
+		tilt.panel()
+		tilt.bits()
+		if got, want := bitPanelString(bitPanel), panel.String(); got != want {
+			t.Fatalf("tilt %d (%s) results in\n%s\ninstead of\n%s", i+1, tilt.name, got, want)
+		}
+	}
+}
+
+func TestExample(t *testing.T) {
+	panel := ParsePanel(helper.ReadNonEmptyLines("example-1.txt"))
+
+	bitPanel := NewBitPanel(panel)
+	bitPanel.TiltNorth()
+	if weight := bitPanel.ComputeNorthWeight(); weight != 136 {
+		t.Errorf("north weight after tilt is %d instead of 136", weight)
+	}
+
+	for _, count := range []int{1, 2, 3, 1000000000} {
+		runePanel := panel.Clone()
+		runePanel.TiltCycles(count)
+		bitPanel := NewBitPanel(panel)
+		bitPanel.TiltCycles(count)
+		if got, want := bitPanelString(bitPanel), runePanel.String(); got != want {
+			t.Errorf("%d cycles result in\n%s\ninstead of\n%s", count, got, want)
+		}
+		if count == 1000000000 {
+			if weight := bitPanel.ComputeNorthWeight(); weight != 64 {
+				t.Errorf("north weight after cycles is %d instead of 64", weight)
+			}
+		}
+	}
+}
@@ -4,19 +4,27 @@ package main
 
 import (
 	"aoc/helper"
+	"flag"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
 func main() {
+	trace := flag.Bool("trace", false, "print all boxes after every step of the initialization sequence")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	initSequences := ParseInitSequences(lines)
 	solution1 := ComputeSumOfHashes(initSequences)
 	fmt.Println("-> part 1:", solution1)
 
-	boxes := ComputeBoxes(initSequences)
+	var tracer func(step string, boxes *Boxes)
+	if *trace {
+		tracer = PrintBoxes
+	}
+	boxes := ComputeBoxes(initSequences, tracer)
 	solution2 := ComputePart2(boxes)
 	fmt.Println("-> part 2:", solution2)
 }
@@ -46,42 +54,41 @@ func HashString(str string) int {
 	return hash
 }
 
-type Lens struct {
-	Label       string
-	FocalLength int
-}
+type Boxes = helper.OrderedHashMap[string, int]
 
-func ComputeBoxes(initSequences []string) map[int][]Lens {
-	boxes := make(map[int][]Lens)
+// ComputeBoxes executes the HASHMAP procedure and calls trace after every step if set.
+func ComputeBoxes(initSequences []string, trace func(step string, boxes *Boxes)) *Boxes {
+	boxes := helper.NewOrderedHashMap[string, int](256, HashString)
 	for _, s := range initSequences {
 		label, action := ParseAction(s)
-		hash := HashString(label)
-		lenses := boxes[hash]
 		if action == "-" {
-			for i := len(lenses) - 1; i >= 0; i-- {
-				if lenses[i].Label == label {
-					lenses = append(lenses[:i], lenses[i+1:]...)
-				}
-			}
+			boxes.Delete(label)
 		} else {
 			focalLength, err := strconv.Atoi(action)
 			helper.ExitOnError(err)
-			found := false
-			for i := range lenses {
-				if lenses[i].Label == label {
-					lenses[i].FocalLength = focalLength
-					found = true
-				}
-			}
-			if !found {
-				lenses = append(lenses, Lens{Label: label, FocalLength: focalLength})
-			}
+			boxes.Put(label, focalLength)
+		}
+		if trace != nil {
+			trace(s, boxes)
 		}
-		boxes[hash] = lenses
 	}
 	return boxes
 }
 
+func PrintBoxes(step string, boxes *Boxes) {
+	fmt.Printf("After %q:\n", step)
+	for i := 0; i < boxes.BucketCount(); i++ {
+		if boxes.BucketLen(i) > 0 {
+			fmt.Printf("Box %d:", i)
+			boxes.RangeBucket(i, func(_ int, label string, focalLength int) {
+				fmt.Printf(" [%s %d]", label, focalLength)
+			})
+			fmt.Println()
+		}
+	}
+	fmt.Println()
+}
+
 func ParseAction(str string) (string, string) {
 	if strings.HasSuffix(str, "-") {
 		return str[:len(str)-1], "-"
@@ -93,12 +100,10 @@ func ParseAction(str string) (string, string) {
 	return parts[0], parts[1]
 }
 
-func ComputePart2(boxes map[int][]Lens) int {
+func ComputePart2(boxes *Boxes) int {
 	var sum int
-	for hash, lenses := range boxes {
-		for i, l := range lenses {
-			sum += (hash + 1) * (i + 1) * l.FocalLength
-		}
-	}
+	boxes.Range(func(box, slot int, _ string, focalLength int) {
+		sum += (box + 1) * (slot + 1) * focalLength
+	})
 	return sum
 }
@@ -4,16 +4,43 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/render"
+	"flag"
 	"fmt"
+	"os"
+	"time"
 )
 
 func main() {
+	animate := flag.Bool("animate", false, "animate the beam spread of part 1 in the terminal")
+	gifFile := flag.String("gif", "", "write animated beam spread of part 1 as GIF to file")
+	pngDir := flag.String("png", "", "write animated beam spread of part 1 as PNG sequence to directory")
+	frameEvery := flag.Int("every", 25, "only keep every n-th frame of the animation")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	board := ParseBoard(lines)
+	if *animate || len(*gifFile) > 0 || len(*pngDir) > 0 {
+		board.Animation = render.NewAnimation(20 * time.Millisecond)
+		board.Animation.Every = *frameEvery
+	}
 	board.FollowBeam(Point{0, 0}, Point{1, 0})
 	solution1 := board.CountEnergizedTiles()
 	fmt.Println("-> part 1:", solution1)
+	if board.Animation != nil {
+		board.Animation.Finish()
+		if *animate {
+			board.Animation.Play(os.Stdout)
+		}
+		if len(*gifFile) > 0 {
+			helper.ExitOnError(board.Animation.WriteGIF(*gifFile, 4), "write gif")
+		}
+		if len(*pngDir) > 0 {
+			helper.ExitOnError(board.Animation.WritePNGs(*pngDir, 4), "write png sequence")
+		}
+		board.Animation = nil
+	}
 
 	board.ResetEnergizedTiles()
 	solution2 := board.FindMaxEnergizedTiles()
@@ -23,6 +50,8 @@ func main() {
 type Board struct {
 	Width, Height int
 	Tiles         [][]Tile
+	// Animation receives a frame for every newly energized tile if not nil.
+	Animation *render.Animation
 }
 
 type Tile struct {
@@ -76,7 +105,12 @@ func (b *Board) followBeam(cache *Cache, pos, dir Point) {
 		return
 	}
 	cache.Visited[key] = true
-	b.Tiles[pos.Y][pos.X].Energized = true
+	if !b.Tiles[pos.Y][pos.X].Energized {
+		b.Tiles[pos.Y][pos.X].Energized = true
+		if b.Animation != nil {
+			b.Animation.AddFunc(b.Canvas)
+		}
+	}
 
 	r := b.Tiles[pos.Y][pos.X].Rune
 	if r == '.' {
@@ -155,6 +189,12 @@ func (b *Board) followBeam(cache *Cache, pos, dir Point) {
 	}
 }
 
+func (b *Board) Canvas() *render.Canvas {
+	canvas := render.NewCanvasFunc(b.Width, b.Height, func(x, y int) rune { return b.Tiles[y][x].Rune })
+	canvas.OverlayFunc("energized", render.Yellow, 0, func(x, y int) bool { return b.Tiles[y][x].Energized })
+	return canvas
+}
+
 func (b *Board) CountEnergizedTiles() int {
 	var count int
 	for y := range b.Tiles {
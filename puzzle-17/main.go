@@ -4,23 +4,49 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/render"
+	"flag"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 func main() {
-	lines := helper.ReadNonEmptyLines("input.txt")
+	showPaths := flag.Bool("show", false, "print found paths")
+	top := flag.Int("top", 1, "find the given number of cheapest paths")
+	diagonal := flag.Bool("diagonal", false, "allow diagonal moves")
+	turnPenalty := flag.Int("turn-penalty", 0, "additional heat loss for every turn")
+	forbidden := flag.String("forbid", "", "space separated list of forbidden cells in the format x,y")
+	flag.Parse()
 
+	lines := helper.ReadNonEmptyLines("input.txt")
 	board := ParseBoard(lines)
-	path1 := board.FindPath(helper.Point2D[int]{X: 0, Y: 0}, helper.Point2D[int]{X: board.Width - 1, Y: board.Height - 1}, 1, 3)
-	//PrintPath(board, path1)
-	solution1 := board.GetPathHeatLoss(path1)
-	fmt.Println("-> part 1:", solution1)
+	forbiddenCells, err := ParsePoints(*forbidden)
+	helper.ExitOnError(err, "parse forbidden cells")
+
+	variant := func(rule MovementRule) MovementRule {
+		if crucible, ok := rule.(Crucible); ok && *diagonal {
+			crucible.Directions = DiagonalDirections
+			rule = crucible
+		}
+		if *turnPenalty != 0 {
+			rule = TurnPenalty{MovementRule: rule, Penalty: *turnPenalty}
+		}
+		if len(forbiddenCells) > 0 {
+			rule = NewForbiddenCells(rule, forbiddenCells...)
+		}
+		return rule
+	}
 
-	path2 := board.FindPath(helper.Point2D[int]{X: 0, Y: 0}, helper.Point2D[int]{X: board.Width - 1, Y: board.Height - 1}, 4, 10)
-	//PrintPath(board, path2)
-	solution2 := board.GetPathHeatLoss(path2)
-	fmt.Println("-> part 2:", solution2)
+	from, to := helper.Point2D[int]{X: 0, Y: 0}, helper.Point2D[int]{X: board.Width - 1, Y: board.Height - 1}
+	for i, rule := range []MovementRule{variant(NormalCrucible), variant(UltraCrucible)} {
+		paths, err := board.FindPaths(from, to, rule, helper.Max(*top, 1))
+		helper.ExitOnError(err, "find paths for part %d", i+1)
+		if *showPaths {
+			PrintPaths(board, paths)
+		}
+		fmt.Printf("-> part %d: %d\n", i+1, paths[0].Cost)
+	}
 }
 
 type Board struct {
@@ -43,100 +69,358 @@ func ParseBoard(lines []string) *Board {
 	}
 }
 
-type PathPoint struct {
-	Previous         *PathPoint
-	Pos              helper.Point2D[int]
-	TotalCost        int
-	SameDirStepCount int
+func ParsePoints(str string) ([]helper.Point2D[int], error) {
+	points := make([]helper.Point2D[int], 0)
+	for _, field := range strings.Fields(str) {
+		parts := strings.Split(field, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid point %q", field)
+		}
+		x, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid point %q", field)
+		}
+		y, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid point %q", field)
+		}
+		points = append(points, helper.Point2D[int]{X: x, Y: y})
+	}
+	return points, nil
+}
+
+func (b *Board) InBounds(p helper.Point2D[int]) bool {
+	return p.InBounds(helper.Point2D[int]{}, helper.Point2D[int]{X: b.Width - 1, Y: b.Height - 1})
+}
+
+// State is a node in the search space of a crucible.
+type State struct {
+	Pos helper.Point2D[int]
+	// Dir is the direction of the last move and zero before the first move.
+	Dir helper.Point2D[int]
+	// Run is the number of consecutive moves in Dir.
+	Run int
+}
+
+type Move struct {
+	To   State
+	Cost int
+}
+
+// MovementRule defines which moves a crucible can make. Move costs must not be negative.
+type MovementRule interface {
+	Moves(b *Board, s State) []Move
+	// CanStop returns whether a path may end in the given state.
+	CanStop(s State) bool
+}
+
+var (
+	OrthogonalDirections = []helper.Point2D[int]{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: -1, Y: 0}}
+	DiagonalDirections   = []helper.Point2D[int]{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: -1}, {X: 0, Y: -1}, {X: -1, Y: -1}, {X: -1, Y: 0}, {X: -1, Y: 1}}
+
+	NormalCrucible = Crucible{MinRun: 1, MaxRun: 3, Directions: OrthogonalDirections}
+	UltraCrucible  = Crucible{MinRun: 4, MaxRun: 10, Directions: OrthogonalDirections}
+)
+
+// Crucible can move in any of its directions but never reverse. It needs to move at least MinRun and at most MaxRun times in the same direction before it can turn or stop.
+type Crucible struct {
+	MinRun, MaxRun int
+	Directions     []helper.Point2D[int]
+}
+
+func (c Crucible) Moves(b *Board, s State) []Move {
+	moves := make([]Move, 0, len(c.Directions))
+	for _, dir := range c.Directions {
+		next := State{Pos: s.Pos.Add(dir), Dir: dir, Run: 1}
+		if dir == s.Dir {
+			if s.Run >= c.MaxRun {
+				continue
+			}
+			next.Run = s.Run + 1
+		} else if dir == s.Dir.Neg() {
+			continue
+		} else if s.Dir != (helper.Point2D[int]{}) && s.Run < c.MinRun {
+			continue
+		}
+		if !b.InBounds(next.Pos) {
+			continue
+		}
+		moves = append(moves, Move{To: next, Cost: b.Tiles[next.Pos.Y][next.Pos.X]})
+	}
+	return moves
+}
+
+func (c Crucible) CanStop(s State) bool {
+	return s.Run >= c.MinRun
+}
+
+// TurnPenalty adds Penalty to the cost of every move that changes the direction.
+type TurnPenalty struct {
+	MovementRule
+	Penalty int
 }
 
-func (b *Board) FindPath(from, to helper.Point2D[int], minDist, maxDist int) []helper.Point2D[int] {
-	type VisitKey struct {
-		Pos              helper.Point2D[int]
-		InDir            helper.Point2D[int]
-		SameDirStepCount int
+func (t TurnPenalty) Moves(b *Board, s State) []Move {
+	moves := t.MovementRule.Moves(b, s)
+	for i := range moves {
+		if s.Dir != (helper.Point2D[int]{}) && moves[i].To.Dir != s.Dir {
+			moves[i].Cost += t.Penalty
+		}
 	}
-	nextValues := helper.MakePriorityQueue[int, *PathPoint]()
-	nextValues.Push(0, &PathPoint{Pos: from, Previous: nil, TotalCost: 0, SameDirStepCount: 1})
-	visited := map[VisitKey]*PathPoint{}
+	return moves
+}
 
-	var bestEndPos *PathPoint
+// ForbiddenCells prevents all moves into the given cells.
+type ForbiddenCells struct {
+	MovementRule
+	Cells map[helper.Point2D[int]]bool
+}
 
-	for nextValues.Len() > 0 {
-		currentPoint, _ := nextValues.Pop()
+func NewForbiddenCells(rule MovementRule, cells ...helper.Point2D[int]) ForbiddenCells {
+	f := ForbiddenCells{MovementRule: rule, Cells: make(map[helper.Point2D[int]]bool, len(cells))}
+	for _, c := range cells {
+		f.Cells[c] = true
+	}
+	return f
+}
 
-		var inDir helper.Point2D[int]
-		if currentPoint.Previous != nil {
-			inDir = currentPoint.Pos.Sub(currentPoint.Previous.Pos)
+func (f ForbiddenCells) Moves(b *Board, s State) []Move {
+	moves := f.MovementRule.Moves(b, s)
+	allowed := moves[:0]
+	for _, m := range moves {
+		if !f.Cells[m.To.Pos] {
+			allowed = append(allowed, m)
 		}
+	}
+	return allowed
+}
 
-		vkey := VisitKey{Pos: currentPoint.Pos, InDir: inDir, SameDirStepCount: currentPoint.SameDirStepCount}
+type Path struct {
+	States []State
+	// Costs contains the cost of the move into each state, the first entry is always zero.
+	Costs []int
+	Cost  int
+}
 
-		if v, ok := visited[vkey]; ok {
-			if currentPoint.TotalCost < v.TotalCost {
-				helper.ExitWithMessage("found better way to %v (%d -> %d)", currentPoint.Pos, v.TotalCost, currentPoint.TotalCost)
-			}
+func (p Path) Points() []helper.Point2D[int] {
+	points := make([]helper.Point2D[int], len(p.States))
+	for i, s := range p.States {
+		points[i] = s.Pos
+	}
+	return points
+}
+
+func (p Path) key() string {
+	var sb strings.Builder
+	for _, s := range p.States {
+		fmt.Fprintf(&sb, "%d,%d,%d,%d,%d;", s.Pos.X, s.Pos.Y, s.Dir.X, s.Dir.Y, s.Run)
+	}
+	return sb.String()
+}
+
+// prefix returns the search nodes of the first n states of the path.
+func (p Path) prefix(n int) *pathNode {
+	var node *pathNode
+	for i := 0; i < n; i++ {
+		next := &pathNode{Previous: node, State: p.States[i], MoveCost: p.Costs[i], TotalCost: p.Costs[i]}
+		if node != nil {
+			next.TotalCost += node.TotalCost
+		}
+		node = next
+	}
+	return node
+}
+
+type pathNode struct {
+	Previous  *pathNode
+	State     State
+	MoveCost  int
+	TotalCost int
+}
+
+type stateMove struct {
+	From, To State
+}
+
+// FindPath returns the cheapest path from one cell to another that only uses moves allowed by rule.
+func (b *Board) FindPath(from, to helper.Point2D[int], rule MovementRule) (Path, error) {
+	end, err := b.search(&pathNode{State: State{Pos: from}}, to, rule, nil, nil, nil)
+	if err != nil {
+		return Path{}, err
+	}
+	if end == nil {
+		return Path{}, fmt.Errorf("no path from %v to %v found", from, to)
+	}
+	return end.path(), nil
+}
+
+// remainingCosts returns the cost of the cheapest path from every state reachable from from to a stoppable state at to. States that cannot reach to are omitted.
+func (b *Board) remainingCosts(from, to helper.Point2D[int], rule MovementRule) (map[State]int, error) {
+	start := State{Pos: from}
+	reverseMoves := map[State][]Move{}
+	known := map[State]bool{start: true}
+	targets := []State{}
+	for queue := []State{start}; len(queue) > 0; queue = queue[1:] {
+		s := queue[0]
+		if s.Pos == to && rule.CanStop(s) {
+			targets = append(targets, s)
 			continue
 		}
-		visited[vkey] = currentPoint
+		for _, m := range rule.Moves(b, s) {
+			if m.Cost < 0 {
+				return nil, fmt.Errorf("negative cost %d for move from %v to %v", m.Cost, s.Pos, m.To.Pos)
+			}
+			reverseMoves[m.To] = append(reverseMoves[m.To], Move{To: s, Cost: m.Cost})
+			if !known[m.To] {
+				known[m.To] = true
+				queue = append(queue, m.To)
+			}
+		}
+	}
 
-		if currentPoint.Pos == to && currentPoint.SameDirStepCount >= minDist {
-			if bestEndPos == nil || currentPoint.TotalCost < bestEndPos.TotalCost {
-				bestEndPos = currentPoint
+	costs := make(map[State]int, len(known))
+	nextStates := helper.MakePriorityQueue[int, State]()
+	for _, s := range targets {
+		nextStates.Push(0, s)
+	}
+	for nextStates.Len() > 0 {
+		s, cost := nextStates.Pop()
+		if _, ok := costs[s]; ok {
+			continue
+		}
+		costs[s] = cost
+		for _, m := range reverseMoves[s] {
+			if _, ok := costs[m.To]; !ok {
+				nextStates.Push(cost+m.Cost, m.To)
 			}
 		}
+	}
+	return costs, nil
+}
 
-		for _, nextDir := range []helper.Point2D[int]{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: -1, Y: 0}} {
-			if nextDir == inDir {
-				if currentPoint.SameDirStepCount >= maxDist {
-					continue
-				}
-			} else if nextDir == inDir.Neg() {
-				continue
-			} else if (inDir == helper.Point2D[int]{}) {
-				// accept
-			} else {
-				if currentPoint.SameDirStepCount < minDist {
-					continue
+// FindPaths returns up to k cheapest paths ordered by cost using Yen's algorithm. Paths never visit the same state twice, but may visit the same cell multiple times.
+func (b *Board) FindPaths(from, to helper.Point2D[int], rule MovementRule, k int) ([]Path, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	first, err := b.FindPath(from, to, rule)
+	if err != nil {
+		return nil, err
+	}
+	if k == 1 {
+		return []Path{first}, nil
+	}
+	// the exact remaining costs without blocked states and moves are a consistent heuristic for all spur searches
+	remaining, err := b.remainingCosts(from, to, rule)
+	if err != nil {
+		return nil, err
+	}
+	paths := []Path{first}
+	known := map[string]bool{first.key(): true}
+	candidates := helper.MakePriorityQueue[int, Path]()
+
+	for len(paths) < k {
+		last := paths[len(paths)-1]
+		for i := 0; i < len(last.States)-1; i++ {
+			// deviate from the last path after its first i+1 states without reusing a move of a known path with the same root
+			root := last.States[:i+1]
+			blockedMoves := map[stateMove]bool{}
+			for _, p := range paths {
+				if len(p.States) > i+1 && equalStates(p.States[:i+1], root) {
+					blockedMoves[stateMove{From: p.States[i], To: p.States[i+1]}] = true
 				}
 			}
+			blockedStates := make(map[State]bool, i)
+			for _, s := range root[:i] {
+				blockedStates[s] = true
+			}
 
-			nextPos := currentPoint.Pos.Add(nextDir)
-			if nextPos.X < 0 || nextPos.Y < 0 || nextPos.X >= b.Width || nextPos.Y >= b.Height {
+			end, err := b.search(last.prefix(i+1), to, rule, blockedStates, blockedMoves, remaining)
+			if err != nil {
+				return nil, err
+			}
+			if end == nil {
 				continue
 			}
-
-			var nextSameDirStepCount int
-			if nextDir == inDir {
-				nextSameDirStepCount = currentPoint.SameDirStepCount + 1
-			} else {
-				nextSameDirStepCount = 1
+			p := end.path()
+			if key := p.key(); !known[key] {
+				known[key] = true
+				candidates.Push(p.Cost, p)
 			}
+		}
+		if candidates.Len() == 0 {
+			break
+		}
+		p, _ := candidates.Pop()
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
 
-			vkeyNext := VisitKey{Pos: nextPos, InDir: nextDir, SameDirStepCount: nextSameDirStepCount}
-			if _, ok := visited[vkeyNext]; ok {
+func equalStates(s1, s2 []State) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// search runs Dijkstra from start to the first stoppable state at to and returns nil if there is no such path.
+// If remaining is set, it runs A* instead and skips all states without remaining costs.
+func (b *Board) search(start *pathNode, to helper.Point2D[int], rule MovementRule, blockedStates map[State]bool, blockedMoves map[stateMove]bool, remaining map[State]int) (*pathNode, error) {
+	nextNodes := helper.MakePriorityQueue[int, *pathNode]()
+	nextNodes.Push(start.TotalCost+remaining[start.State], start)
+	visited := map[State]bool{}
+
+	for nextNodes.Len() > 0 {
+		current, _ := nextNodes.Pop()
+		if visited[current.State] {
+			continue
+		}
+		visited[current.State] = true
+
+		if current.State.Pos == to && rule.CanStop(current.State) {
+			return current, nil
+		}
+
+		for _, m := range rule.Moves(b, current.State) {
+			if m.Cost < 0 {
+				// Dijkstra would not be able to guarantee an optimal path
+				return nil, fmt.Errorf("negative cost %d for move from %v to %v", m.Cost, current.State.Pos, m.To.Pos)
+			}
+			if visited[m.To] || blockedStates[m.To] || blockedMoves[stateMove{From: current.State, To: m.To}] {
 				continue
 			}
-
-			nextPoint := PathPoint{
-				Pos:              nextPos,
-				Previous:         currentPoint,
-				TotalCost:        currentPoint.TotalCost + b.Tiles[nextPos.Y][nextPos.X],
-				SameDirStepCount: nextSameDirStepCount,
+			var estimate int
+			if remaining != nil {
+				var ok bool
+				if estimate, ok = remaining[m.To]; !ok {
+					continue
+				}
 			}
-			nextValues.Push(nextPoint.TotalCost, &nextPoint)
+			next := &pathNode{Previous: current, State: m.To, MoveCost: m.Cost, TotalCost: current.TotalCost + m.Cost}
+			nextNodes.Push(next.TotalCost+estimate, next)
 		}
 	}
+	return nil, nil
+}
 
-	if bestEndPos == nil {
-		helper.ExitWithMessage("no path from %v to %v found", from, to)
+func (n *pathNode) path() Path {
+	nodes := []*pathNode{}
+	for current := n; current != nil; current = current.Previous {
+		nodes = append(nodes, current)
 	}
-
-	path := []helper.Point2D[int]{}
-	for current := bestEndPos; current != nil; current = current.Previous {
-		path = append([]helper.Point2D[int]{current.Pos}, path...)
+	p := Path{States: make([]State, len(nodes)), Costs: make([]int, len(nodes)), Cost: n.TotalCost}
+	for i := range nodes {
+		node := nodes[len(nodes)-1-i]
+		p.States[i] = node.State
+		p.Costs[i] = node.MoveCost
 	}
-	return path
+	return p
 }
 
 func (b *Board) GetPathHeatLoss(path []helper.Point2D[int]) int {
@@ -147,20 +431,50 @@ func (b *Board) GetPathHeatLoss(path []helper.Point2D[int]) int {
 	return heatLoss
 }
 
-func PrintPath(board *Board, path []helper.Point2D[int]) {
-	runeLines := make([][]rune, board.Height)
-	for y := 0; y < board.Height; y++ {
-		runeLines[y] = make([]rune, len(board.Tiles[y]))
-		for x := 0; x < board.Width; x++ {
-			runeLines[y][x] = '0' + rune(board.Tiles[y][x])
+var dirSymbols = map[helper.Point2D[int]]rune{
+	{X: 1, Y: 0}: '>', {X: -1, Y: 0}: '<', {X: 0, Y: 1}: 'v', {X: 0, Y: -1}: '^',
+	{X: 1, Y: 1}: '\\', {X: -1, Y: -1}: '\\', {X: 1, Y: -1}: '/', {X: -1, Y: 1}: '/',
+}
+
+// RenderPath draws the board with the run length of every path cell. Cells where the path turns show the direction of the next move instead.
+func RenderPath(board *Board, path Path) *render.Canvas {
+	symbols := make(map[helper.Point2D[int]]rune, len(path.States))
+	turns := make([]helper.Point2D[int], 0)
+	for i, s := range path.States {
+		if i+1 < len(path.States) && path.States[i+1].Dir != s.Dir {
+			symbols[s.Pos] = dirSymbols[path.States[i+1].Dir]
+			turns = append(turns, s.Pos)
+		} else {
+			symbols[s.Pos] = []rune(strconv.FormatInt(int64(s.Run), 36))[0]
 		}
 	}
-	for _, p := range path {
-		runeLines[p.Y][p.X] = '#'
+	canvas := render.NewCanvasFunc(board.Width, board.Height, func(x, y int) rune {
+		if r, ok := symbols[helper.Point2D[int]{X: x, Y: y}]; ok {
+			return r
+		}
+		return '0' + rune(board.Tiles[y][x])
+	})
+	canvas.Overlay("path", render.Red, 0, path.Points())
+	canvas.Overlay("turns", render.Yellow, 0, turns)
+	return canvas
+}
+
+// ExplainPath lists all moves of a path with their cost and run length.
+func ExplainPath(board *Board, path Path) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%5s  %9s  %3s  %3s  %4s  %4s  %5s\n", "step", "pos", "dir", "run", "heat", "cost", "total")
+	var total int
+	for i, s := range path.States[1:] {
+		total += path.Costs[i+1]
+		fmt.Fprintf(&sb, "%5d  %9s  %3c  %3d  %4d  %4d  %5d\n", i+1, fmt.Sprintf("%d,%d", s.Pos.X, s.Pos.Y), dirSymbols[s.Dir], s.Run, board.Tiles[s.Pos.Y][s.Pos.X], path.Costs[i+1], total)
 	}
-	lines := make([]string, len(runeLines))
-	for y := 0; y < board.Height; y++ {
-		lines[y] = string(runeLines[y])
+	return sb.String()
+}
+
+func PrintPaths(board *Board, paths []Path) {
+	for i, p := range paths {
+		fmt.Printf("path #%d with cost %d:\n", i+1, p.Cost)
+		fmt.Println(RenderPath(board, p).ANSI())
+		fmt.Println(ExplainPath(board, p))
 	}
-	fmt.Println(strings.Join(lines, "\n"))
 }
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"aoc/helper"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	for _, tc := range []struct {
+		file  string
+		rule  MovementRule
+		label string
+		want  int
+	}{
+		{"example-1.txt", NormalCrucible, "part 1", 102},
+		{"example-1.txt", UltraCrucible, "part 2", 94},
+		{"example-2.txt", UltraCrucible, "part 2", 71},
+	} {
+		board := ParseBoard(helper.ReadNonEmptyLines(tc.file))
+		path, err := board.FindPath(helper.Point2D[int]{}, helper.Point2D[int]{X: board.Width - 1, Y: board.Height - 1}, tc.rule)
+		if err != nil {
+			t.Fatalf("%s %s: %s", tc.file, tc.label, err.Error())
+		}
+		if path.Cost != tc.want {
+			t.Errorf("%s %s is %d instead of %d", tc.file, tc.label, path.Cost, tc.want)
+		}
+		if heatLoss := board.GetPathHeatLoss(path.Points()); heatLoss != path.Cost {
+			t.Errorf("%s %s: path has heat loss %d, but cost %d", tc.file, tc.label, heatLoss, path.Cost)
+		}
+	}
+}
+
+func TestRules(t *testing.T) {
+	board := ParseBoard([]string{
+		"1111",
+		"9991",
+		"1111",
+	})
+	from, to := helper.Point2D[int]{}, helper.Point2D[int]{X: 3, Y: 2}
+
+	for _, tc := range []struct {
+		name string
+		rule MovementRule
+		want int
+	}{
+		{"normal", NormalCrucible, 5},
+		// the path along the border only turns once
+		{"turn penalty", TurnPenalty{MovementRule: NormalCrucible, Penalty: 10}, 15},
+		// without the top right corner, the crucible has to cross a 9
+		{"forbidden", NewForbiddenCells(NormalCrucible, helper.Point2D[int]{X: 3, Y: 0}), 13},
+		// cutting the corner diagonally saves one step, all paths with three steps cross a 9
+		{"diagonal", Crucible{MinRun: 1, MaxRun: 3, Directions: DiagonalDirections}, 4},
+	} {
+		path, err := board.FindPath(from, to, tc.rule)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err.Error())
+		}
+		if path.Cost != tc.want {
+			t.Errorf("%s: cost is %d instead of %d", tc.name, path.Cost, tc.want)
+		}
+	}
+
+	blocked := NewForbiddenCells(NormalCrucible, helper.Point2D[int]{X: 3, Y: 1}, helper.Point2D[int]{X: 2, Y: 2})
+	if _, err := board.FindPath(from, to, blocked); err == nil {
+		t.Errorf("found path to a cell enclosed by forbidden cells")
+	}
+}
+
+// TestFindPathBruteForce compares FindPath with the minimum cost of all paths on small random boards.
+func TestFindPathBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(17))
+	rules := []struct {
+		name string
+		rule MovementRule
+	}{
+		{"normal", NormalCrucible},
+		{"ultra", Crucible{MinRun: 2, MaxRun: 4, Directions: OrthogonalDirections}},
+		{"turn penalty", TurnPenalty{MovementRule: NormalCrucible, Penalty: 2}},
+		{"diagonal", Crucible{MinRun: 1, MaxRun: 2, Directions: DiagonalDirections}},
+	}
+	for i := 0; i < 50; i++ {
+		width, height := 2+rnd.Intn(3), 2+rnd.Intn(3)
+		lines := make([]string, height)
+		for y := range lines {
+			line := make([]byte, width)
+			for x := range line {
+				line[x] = byte('1' + rnd.Intn(9))
+			}
+			lines[y] = string(line)
+		}
+		board := ParseBoard(lines)
+		from, to := helper.Point2D[int]{}, helper.Point2D[int]{X: width - 1, Y: height - 1}
+
+		for _, r := range rules {
+			rule := r.rule
+			if rnd.Intn(2) == 0 {
+				rule = NewForbiddenCells(rule, helper.Point2D[int]{X: rnd.Intn(width), Y: rnd.Intn(height)})
+			}
+			want, found := bruteForceCost(board, from, to, rule)
+			path, err := board.FindPath(from, to, rule)
+			if !found {
+				if err == nil {
+					t.Errorf("%v %s: found path with cost %d, but brute force found none", lines, r.name, path.Cost)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%v %s: %s, but brute force found cost %d", lines, r.name, err.Error(), want)
+			} else if path.Cost != want {
+				t.Errorf("%v %s: cost is %d instead of %d", lines, r.name, path.Cost, want)
+			}
+		}
+	}
+}
+
+// bruteForceCost relaxes the costs of all reachable states until nothing changes, which does not depend on the order in which states are visited.
+func bruteForceCost(board *Board, from, to helper.Point2D[int], rule MovementRule) (int, bool) {
+	isEnd := func(s State) bool { return s.Pos == to && rule.CanStop(s) }
+	costs := map[State]int{{Pos: from}: 0}
+	for changed := true; changed; {
+		changed = false
+		states := make([]State, 0, len(costs))
+		for s := range costs {
+			states = append(states, s)
+		}
+		for _, s := range states {
+			if isEnd(s) {
+				// like FindPath, paths end at the first stoppable state at the target
+				continue
+			}
+			for _, m := range rule.Moves(board, s) {
+				if c, ok := costs[m.To]; !ok || costs[s]+m.Cost < c {
+					costs[m.To] = costs[s] + m.Cost
+					changed = true
+				}
+			}
+		}
+	}
+	best, found := 0, false
+	for s, c := range costs {
+		if isEnd(s) && (!found || c < best) {
+			best, found = c, true
+		}
+	}
+	return best, found
+}
+
+func TestFindPaths(t *testing.T) {
+	board := ParseBoard(helper.ReadNonEmptyLines("example-1.txt"))
+	from, to := helper.Point2D[int]{}, helper.Point2D[int]{X: board.Width - 1, Y: board.Height - 1}
+
+	if paths, err := board.FindPaths(from, to, NormalCrucible, 0); err != nil || len(paths) != 0 {
+		t.Errorf("k=0 returns %d paths and error %v", len(paths), err)
+	}
+	paths, err := board.FindPaths(from, to, NormalCrucible, 5)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(paths) != 5 {
+		t.Fatalf("found %d instead of 5 paths", len(paths))
+	}
+	if paths[0].Cost != 102 {
+		t.Errorf("cheapest path costs %d instead of 102", paths[0].Cost)
+	}
+	known := make(map[string]bool)
+	for i, path := range paths {
+		if i > 0 && path.Cost < paths[i-1].Cost {
+			t.Errorf("path %d costs %d, less than previous path with %d", i, path.Cost, paths[i-1].Cost)
+		}
+		if known[path.key()] {
+			t.Errorf("path %d is a duplicate", i)
+		}
+		known[path.key()] = true
+	}
+}
+
+// TestFindPathsBruteForce compares the costs of the k paths with the k cheapest of all paths that never visit the same state twice.
+func TestFindPathsBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(45))
+	for i := 0; i < 20; i++ {
+		width, height := 3+rnd.Intn(2), 3
+		lines := make([]string, height)
+		for y := range lines {
+			line := make([]byte, width)
+			for x := range line {
+				line[x] = byte('1' + rnd.Intn(9))
+			}
+			lines[y] = string(line)
+		}
+		board := ParseBoard(lines)
+		from, to := helper.Point2D[int]{}, helper.Point2D[int]{X: width - 1, Y: height - 1}
+
+		for _, rule := range []MovementRule{NormalCrucible, TurnPenalty{MovementRule: NormalCrucible, Penalty: 3}} {
+			all := bruteForcePathCosts(board, from, to, rule)
+			sort.Ints(all)
+			const k = 10
+			paths, err := board.FindPaths(from, to, rule, k)
+			if err != nil {
+				t.Fatalf("%v: %s", lines, err.Error())
+			}
+			want := all[:helper.Min(k, len(all))]
+			got := make([]int, len(paths))
+			for j, p := range paths {
+				got[j] = p.Cost
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%v: paths cost %v instead of %v", lines, got, want)
+			}
+		}
+	}
+}
+
+// bruteForcePathCosts returns the costs of all paths that end at the first stoppable state at the target and never visit the same state twice.
+func bruteForcePathCosts(board *Board, from, to helper.Point2D[int], rule MovementRule) []int {
+	costs := make([]int, 0)
+	visited := make(map[State]bool)
+	var walk func(s State, cost int)
+	walk = func(s State, cost int) {
+		if s.Pos == to && rule.CanStop(s) {
+			costs = append(costs, cost)
+			return
+		}
+		visited[s] = true
+		for _, m := range rule.Moves(board, s) {
+			if !visited[m.To] {
+				walk(m.To, cost+m.Cost)
+			}
+		}
+		visited[s] = false
+	}
+	walk(State{Pos: from}, 0)
+	return costs
+}
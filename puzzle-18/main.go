@@ -4,23 +4,47 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/render"
+	"errors"
+	"flag"
 	"fmt"
 	"regexp"
 	"strconv"
 )
 
 func main() {
-	lines := helper.ReadNonEmptyLines("input.txt")
+	show := flag.Bool("show", false, "print the lagoon of part 1 using the colors of the dig instructions")
+	pngFile := flag.String("png", "", "write the lagoon of part 1 to the given PNG file")
+	flag.Parse()
 
-	digInstructions := ParseDigInstructions(lines)
+	lines := helper.ReadLines("input.txt")
+
+	digInstructions, err := ParseDigInstructions(lines)
+	helper.ExitOnError(err, "parse dig instructions")
+	helper.ExitOnError(ValidateDigInstructions(digInstructions), "validate dig instructions")
+	if *show {
+		fmt.Println(RenderLagoon(digInstructions).ANSI())
+	}
+	if len(*pngFile) > 0 {
+		helper.ExitOnError(RenderLagoon(digInstructions).WritePNG(*pngFile, 2), "write lagoon image")
+	}
 	solution1 := CountInsideTiles(digInstructions)
+	crossCheck(solution1, CountInsideTilesCompressed(digInstructions))
 	fmt.Println("-> part 1:", solution1)
 
 	digInstructions2 := TransformDigInstructions(digInstructions)
+	helper.ExitOnError(ValidateDigInstructions(digInstructions2), "validate transformed dig instructions")
 	solution2 := CountInsideTiles(digInstructions2)
+	crossCheck(solution2, CountInsideTilesCompressed(digInstructions2))
 	fmt.Println("-> part 2:", solution2)
 }
 
+func crossCheck(shoelace, floodFill int64) {
+	if shoelace != floodFill {
+		helper.ExitWithMessage("shoelace formula counted %d tiles, but flood fill counted %d", shoelace, floodFill)
+	}
+}
+
 type DigInstruction struct {
 	Pos helper.Point2D[int]
 	Dir helper.Point2D[int]
@@ -28,37 +52,144 @@ type DigInstruction struct {
 	RGB string
 }
 
-func ParseDigInstructions(lines []string) []DigInstruction {
-	pattern := regexp.MustCompile(`^([UDLR]+)\s+(\d+)\s+\(#([0-9a-f]{6})\)$`)
+func (di DigInstruction) End() helper.Point2D[int] {
+	return di.Pos.Add(di.Dir.Mul(di.Len))
+}
+
+func (di DigInstruction) Color() render.Color {
+	rgb, _ := strconv.ParseUint(di.RGB, 16, 32)
+	return render.RGB(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb))
+}
+
+// ParseDigInstructions ignores empty lines and reports all invalid lines with their line number.
+func ParseDigInstructions(lines []string) ([]DigInstruction, error) {
+	pattern := regexp.MustCompile(`^([UDLR])\s+(\d+)\s+\(#([0-9a-f]{6})\)$`)
 
 	nextPos := helper.Point2D[int]{X: 0, Y: 0}
 
-	digInstructions := make([]DigInstruction, len(lines))
+	digInstructions := make([]DigInstruction, 0, len(lines))
+	var errs []error
 	for i := range lines {
+		if len(lines[i]) == 0 {
+			continue
+		}
 		m := pattern.FindStringSubmatch(lines[i])
-		if len(m) == 4 {
-			length, _ := strconv.Atoi(m[2])
-			var dir helper.Point2D[int]
-			switch m[1] {
-			case "U":
-				dir = helper.Point2D[int]{X: 0, Y: -1}
-			case "D":
-				dir = helper.Point2D[int]{X: 0, Y: 1}
-			case "L":
-				dir = helper.Point2D[int]{X: -1, Y: 0}
-			case "R":
-				dir = helper.Point2D[int]{X: 1, Y: 0}
+		if len(m) != 4 {
+			errs = append(errs, fmt.Errorf("line %d: invalid dig instruction %q", i+1, lines[i]))
+			continue
+		}
+		length, _ := strconv.Atoi(m[2])
+		var dir helper.Point2D[int]
+		switch m[1] {
+		case "U":
+			dir = helper.Point2D[int]{X: 0, Y: -1}
+		case "D":
+			dir = helper.Point2D[int]{X: 0, Y: 1}
+		case "L":
+			dir = helper.Point2D[int]{X: -1, Y: 0}
+		case "R":
+			dir = helper.Point2D[int]{X: 1, Y: 0}
+		}
+		digInstructions = append(digInstructions, DigInstruction{
+			Pos: nextPos,
+			Dir: dir,
+			Len: length,
+			RGB: m[3],
+		})
+		nextPos = nextPos.Add(dir.Mul(length))
+	}
+	return digInstructions, errors.Join(errs...)
+}
+
+// ValidateDigInstructions checks that the trench is a simple closed polygon without zero-length legs, which is required by CountInsideTiles.
+// It compares all pairs of legs, which is quadratic but takes only milliseconds for the ~700 instructions of the puzzle input.
+func ValidateDigInstructions(digInstructions []DigInstruction) error {
+	if len(digInstructions) == 0 {
+		return fmt.Errorf("no dig instructions")
+	}
+	for i, di := range digInstructions {
+		if di.Len <= 0 {
+			return fmt.Errorf("instruction %d has length %d", i+1, di.Len)
+		}
+	}
+	if end := digInstructions[len(digInstructions)-1].End(); end != digInstructions[0].Pos {
+		return fmt.Errorf("trench ends at %v instead of %v", end, digInstructions[0].Pos)
+	}
+
+	for i := range digInstructions {
+		for j := i + 1; j < len(digInstructions); j++ {
+			di1, di2 := digInstructions[i], digInstructions[j]
+			if j == i+1 || (i == 0 && j == len(digInstructions)-1) {
+				// consecutive instructions always share one end, so they only overlap if the second one goes back
+				if di1.Dir == di2.Dir.Neg() {
+					return fmt.Errorf("instruction %d reverses instruction %d", j+1, i+1)
+				}
+			} else if segmentsTouch(di1.Pos, di1.End(), di2.Pos, di2.End()) {
+				return fmt.Errorf("instructions %d and %d intersect", i+1, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+// segmentsTouch returns whether two axis-aligned segments share at least one point.
+func segmentsTouch(a1, a2, b1, b2 helper.Point2D[int]) bool {
+	return helper.Min(a1.X, a2.X) <= helper.Max(b1.X, b2.X) && helper.Min(b1.X, b2.X) <= helper.Max(a1.X, a2.X) &&
+		helper.Min(a1.Y, a2.Y) <= helper.Max(b1.Y, b2.Y) && helper.Min(b1.Y, b2.Y) <= helper.Max(a1.Y, a2.Y)
+}
+
+// RenderLagoon draws the trench in the colors of the dig instructions and the dug out interior in gray.
+func RenderLagoon(digInstructions []DigInstruction) *render.Canvas {
+	var min, max helper.Point2D[int]
+	for _, di := range digInstructions {
+		min = helper.Point2D[int]{X: helper.Min(min.X, di.Pos.X), Y: helper.Min(min.Y, di.Pos.Y)}
+		max = helper.Point2D[int]{X: helper.Max(max.X, di.Pos.X), Y: helper.Max(max.Y, di.Pos.Y)}
+	}
+	// keep a border of one tile around the trench so the outside is connected
+	offset := helper.Point2D[int]{X: 1, Y: 1}.Sub(min)
+	width, height := max.X-min.X+3, max.Y-min.Y+3
+
+	trench := make(map[helper.Point2D[int]]bool)
+	trenchByRGB := make(map[string][]helper.Point2D[int])
+	for _, di := range digInstructions {
+		for i := 0; i < di.Len; i++ {
+			p := di.Pos.Add(di.Dir.Mul(i)).Add(offset)
+			trench[p] = true
+			trenchByRGB[di.RGB] = append(trenchByRGB[di.RGB], p)
+		}
+	}
+
+	outside := map[helper.Point2D[int]]bool{{X: 0, Y: 0}: true}
+	for queue := []helper.Point2D[int]{{X: 0, Y: 0}}; len(queue) > 0; queue = queue[1:] {
+		for _, dir := range []helper.Point2D[int]{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: -1, Y: 0}} {
+			next := queue[0].Add(dir)
+			if next.X < 0 || next.Y < 0 || next.X >= width || next.Y >= height || outside[next] {
+				continue
 			}
-			digInstructions[i] = DigInstruction{
-				Pos: nextPos,
-				Dir: dir,
-				Len: length,
-				RGB: m[3],
+			if !trench[next] {
+				outside[next] = true
+				queue = append(queue, next)
 			}
-			nextPos = nextPos.Add(dir.Mul(length))
 		}
 	}
-	return digInstructions
+
+	canvas := render.NewCanvasFunc(width, height, func(x, y int) rune {
+		p := helper.Point2D[int]{X: x, Y: y}
+		if trench[p] {
+			return '#'
+		}
+		if outside[p] {
+			return ' '
+		}
+		return '.'
+	})
+	canvas.OverlayFunc("lagoon", render.Gray, 0, func(x, y int) bool {
+		return canvas.Tiles[y][x] == '.'
+	})
+	helper.IterateMapInKeyOrder(trenchByRGB, func(rgb string, points []helper.Point2D[int]) {
+		canvas.Overlay("#"+rgb, DigInstruction{RGB: rgb}.Color(), 0, points)
+	})
+	return canvas
 }
 
 func CountInsideTiles(digInstructions []DigInstruction) int64 {
@@ -76,6 +207,25 @@ func CountInsideTiles(digInstructions []DigInstruction) int64 {
 	return int64(count)/2 + CountBoundaryTiles(digInstructions)/2 + 1
 }
 
+// CountInsideTilesCompressed flood fills the outside of the trench on a compressed grid, which does not depend on the trench orientation or Pick's theorem.
+func CountInsideTilesCompressed(digInstructions []DigInstruction) int64 {
+	// every tile is the unit square [x, x+1) x [y, y+1), with one tile of margin to connect the outside
+	xs, ys := []int{}, []int{}
+	for _, di := range digInstructions {
+		xs = append(xs, di.Pos.X-1, di.Pos.X, di.Pos.X+1, di.Pos.X+2)
+		ys = append(ys, di.Pos.Y-1, di.Pos.Y, di.Pos.Y+1, di.Pos.Y+2)
+	}
+	grid := helper.NewCompressedGrid(helper.NewCompressedAxis(xs...), helper.NewCompressedAxis(ys...))
+	for _, di := range digInstructions {
+		start, end := di.Pos, di.End()
+		from := []int{helper.Min(start.X, end.X), helper.Min(start.Y, end.Y)}
+		to := []int{helper.Max(start.X, end.X) + 1, helper.Max(start.Y, end.Y) + 1}
+		grid.Block(from, to)
+	}
+	_, outside := grid.FloodFill(0, 0)
+	return grid.TotalWeight() - outside
+}
+
 func CountBoundaryTiles(digInstructions []DigInstruction) int64 {
 	var count int64
 	for _, di := range digInstructions {
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"aoc/helper"
+	"strings"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	digInstructions, err := ParseDigInstructions(helper.ReadNonEmptyLines("example-1.txt"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := ValidateDigInstructions(digInstructions); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got := CountInsideTiles(digInstructions); got != 62 {
+		t.Errorf("part 1 is %d instead of 62", got)
+	}
+	if got := CountInsideTilesCompressed(digInstructions); got != 62 {
+		t.Errorf("part 1 with flood fill is %d instead of 62", got)
+	}
+
+	digInstructions2 := TransformDigInstructions(digInstructions)
+	if err := ValidateDigInstructions(digInstructions2); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got := CountInsideTiles(digInstructions2); got != 952408144115 {
+		t.Errorf("part 2 is %d instead of 952408144115", got)
+	}
+}
+
+func TestValidateDigInstructions(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		lines []string
+		err   string
+	}{
+		{"square", []string{"R 2 (#000000)", "D 2 (#000000)", "L 2 (#000000)", "U 2 (#000000)"}, ""},
+		// two squares that share one corner
+		{"touching corner", []string{"R 1 (#000000)", "D 1 (#000000)", "R 1 (#000000)", "D 1 (#000000)", "L 1 (#000000)", "U 1 (#000000)", "L 1 (#000000)", "U 1 (#000000)"}, "instructions 2 and 6 intersect"},
+		{"no instructions", nil, "no dig instructions"},
+		{"zero-length step", []string{"R 2 (#000000)", "D 0 (#000000)", "D 2 (#000000)", "L 2 (#000000)", "U 2 (#000000)"}, "instruction 2 has length 0"},
+		{"unclosed", []string{"R 2 (#000000)", "D 2 (#000000)", "L 2 (#000000)"}, "trench ends at"},
+		{"reversal", []string{"R 2 (#000000)", "L 2 (#000000)"}, "instruction 2 reverses instruction 1"},
+		// the fourth instruction goes up through the first one and comes back around it
+		{"crossing", []string{"R 2 (#000000)", "D 2 (#000000)", "L 1 (#000000)", "U 3 (#000000)", "L 1 (#000000)", "D 1 (#000000)"}, "instructions 1 and 4 intersect"},
+		// the fourth instruction ends on the first one
+		{"touching leg", []string{"R 3 (#000000)", "D 1 (#000000)", "L 1 (#000000)", "U 1 (#000000)", "L 1 (#000000)", "D 2 (#000000)", "L 1 (#000000)", "U 2 (#000000)"}, "instructions 1 and 4 intersect"},
+	} {
+		digInstructions, err := ParseDigInstructions(tc.lines)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err.Error())
+		}
+		err = ValidateDigInstructions(digInstructions)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %q", tc.name, err.Error())
+		case tc.err != "" && err == nil:
+			t.Errorf("%s: no error, expected %q", tc.name, tc.err)
+		case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+			t.Errorf("%s: error %q does not contain %q", tc.name, err.Error(), tc.err)
+		}
+	}
+}
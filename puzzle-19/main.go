@@ -4,6 +4,8 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/dot"
+	"flag"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -11,9 +13,15 @@ import (
 )
 
 func main() {
+	dotFile := flag.String("dot", "", "write workflow graph as DOT graph to file")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	system, partRatings := ParseInput(lines)
+	if len(*dotFile) > 0 {
+		helper.ExitOnError(system.ToDot().WriteFile(*dotFile), "write dot file")
+	}
 	acceptedParts := system.GetAcceptedParts(partRatings)
 	solution1 := SumCategoryValues(acceptedParts)
 	fmt.Println("-> part 1:", solution1)
@@ -147,6 +155,38 @@ func (r Rule) Matches(p PartRating) bool {
 	return false
 }
 
+func (r Rule) String() string {
+	if r.Category == 0 || r.Operator == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%c%c%d", r.Category, r.Operator, r.Value)
+}
+
+func (s System) ToDot() *dot.Graph {
+	g := dot.NewGraph("workflows", true)
+	g.NodeStyle = dot.Attributes{"shape": "box"}
+	g.AddNode("in", dot.Attributes{"shape": "house", "style": "bold"})
+	g.AddNode("A", dot.Attributes{"shape": "doublecircle", "label": "accepted", "style": "filled", "fillcolor": "palegreen"})
+	g.AddNode("R", dot.Attributes{"shape": "doublecircle", "label": "rejected", "style": "filled", "fillcolor": "salmon"})
+	helper.IterateMapInKeyOrder(s.Workflows, func(name string, w Workflow) {
+		g.AddNode(name, nil)
+		for i, r := range w.Rules {
+			attrs := dot.Attributes{"label": r.String()}
+			if i == len(w.Rules)-1 && len(attrs["label"]) == 0 {
+				attrs = dot.Attributes{"style": "dashed"}
+			}
+			switch r.NextWorkflow {
+			case "A":
+				attrs["color"] = "darkgreen"
+			case "R":
+				attrs["color"] = "red"
+			}
+			g.AddEdge(name, r.NextWorkflow, attrs)
+		}
+	})
+	return g
+}
+
 type PartRange struct {
 	Categories map[rune]ValRange
 }
@@ -4,16 +4,31 @@ package main
 
 import (
 	"aoc/helper"
+	"flag"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: puzzle-2 [query args...]")
+		fmt.Fprintln(flag.CommandLine.Output(), "  feasible <bag>     list games possible with the bag, e.g. red=12,green=13,blue=14")
+		fmt.Fprintln(flag.CommandLine.Output(), "  minbags            print the minimal bag of every game")
+		fmt.Fprintln(flag.CommandLine.Output(), "  frontier <k>       print all minimal bags that admit at least k games")
+	}
+	flag.Parse()
+
 	lines := helper.ReadLines("input.txt")
 
 	games := parseGames(lines)
+	if flag.NArg() > 0 {
+		runQuery(games, flag.Args())
+		return
+	}
+
 	solution1 := sumPossibleGameIDs(games, Bag{
 		ColorRed:   12,
 		ColorGreen: 13,
@@ -68,7 +83,7 @@ func parseGame(line string) Game {
 	return Game{ID: id, Sets: sets}
 }
 
-var patternCube = regexp.MustCompile(`(\d+)\s+(red|green|blue)`)
+var patternCube = regexp.MustCompile(`(\d+)\s+([a-z]+)`)
 
 func parseSet(str string) Set {
 	matches := patternCube.FindAllStringSubmatch(str, -1)
@@ -146,3 +161,170 @@ func (bag Bag) Power() int {
 	}
 	return power
 }
+
+func (bag Bag) String() string {
+	parts := make([]string, 0, len(bag))
+	helper.IterateMapInKeyOrder(bag, func(c Color, v int) {
+		parts = append(parts, fmt.Sprintf("%s=%d", c, v))
+	})
+	return strings.Join(parts, ",")
+}
+
+// Contains returns true if the bag has at least as many cubes of every color as the other bag.
+func (bag Bag) Contains(other Bag) bool {
+	for c, v := range other {
+		if bag[c] < v {
+			return false
+		}
+	}
+	return true
+}
+
+func ParseBag(str string) (Bag, error) {
+	bag := make(Bag)
+	for _, part := range helper.SplitAndTrim(str, ",") {
+		kv := helper.SplitAndTrim(part, "=")
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("malformed bag entry %q", part)
+		}
+		num, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cube count in %q", part)
+		}
+		bag[Color(kv[0])] = num
+	}
+	return bag, nil
+}
+
+func GetPossibleGames(games []Game, bag Bag) []Game {
+	possibleGames := make([]Game, 0)
+	for _, g := range games {
+		if g.IsPossible(bag) {
+			possibleGames = append(possibleGames, g)
+		}
+	}
+	return possibleGames
+}
+
+func GetColors(games []Game) []Color {
+	colorSet := make(map[Color]bool)
+	for _, g := range games {
+		for _, set := range g.Sets {
+			for c := range set {
+				colorSet[c] = true
+			}
+		}
+	}
+	colors := make([]Color, 0, len(colorSet))
+	helper.IterateMapInKeyOrder(colorSet, func(c Color, _ bool) {
+		colors = append(colors, c)
+	})
+	return colors
+}
+
+// GetMinimalBagsForGameCount returns the Pareto frontier of all bags that admit at least k games,
+// i.e. all bags where removing any single cube would admit fewer than k games.
+func GetMinimalBagsForGameCount(games []Game, k int) []Bag {
+	minBags := make([]Bag, len(games))
+	for i, g := range games {
+		minBags[i] = g.GetMinBag()
+	}
+	colors := GetColors(games)
+
+	// cube counts of minimal bags always equal the minimal cube count of some game
+	candidateValues := make([][]int, len(colors))
+	for i, c := range colors {
+		values := map[int]bool{0: true}
+		for _, b := range minBags {
+			values[b[c]] = true
+		}
+		for v := range values {
+			candidateValues[i] = append(candidateValues[i], v)
+		}
+		sort.Ints(candidateValues[i])
+	}
+
+	countAdmitted := func(valueIndices []int) int {
+		bag := make(Bag, len(colors))
+		for i, c := range colors {
+			bag[c] = candidateValues[i][valueIndices[i]]
+		}
+		var count int
+		for _, b := range minBags {
+			if bag.Contains(b) {
+				count++
+			}
+		}
+		return count
+	}
+
+	// admitted games grow monotonically with the bag, so it suffices to check single steps towards smaller bags
+	frontier := make([]Bag, 0)
+	valueIndices := make([]int, len(colors))
+	var iterate func(colorIndex int)
+	iterate = func(colorIndex int) {
+		if colorIndex < len(colors) {
+			for i := range candidateValues[colorIndex] {
+				valueIndices[colorIndex] = i
+				iterate(colorIndex + 1)
+			}
+			return
+		}
+		if countAdmitted(valueIndices) < k {
+			return
+		}
+		for i := range valueIndices {
+			if valueIndices[i] > 0 {
+				valueIndices[i]--
+				smallerCount := countAdmitted(valueIndices)
+				valueIndices[i]++
+				if smallerCount >= k {
+					return
+				}
+			}
+		}
+		bag := make(Bag, len(colors))
+		for i, c := range colors {
+			bag[c] = candidateValues[i][valueIndices[i]]
+		}
+		frontier = append(frontier, bag)
+	}
+	iterate(0)
+	return frontier
+}
+
+func runQuery(games []Game, args []string) {
+	switch args[0] {
+	case "feasible":
+		if len(args) != 2 {
+			helper.ExitWithMessage("usage: feasible <bag>")
+		}
+		bag, err := ParseBag(args[1])
+		helper.ExitOnError(err, "parse bag")
+		possibleGames := GetPossibleGames(games, bag)
+		ids := make([]string, len(possibleGames))
+		for i, g := range possibleGames {
+			ids[i] = strconv.Itoa(g.ID)
+		}
+		fmt.Printf("%d of %d games possible with %s: %s\n", len(possibleGames), len(games), bag, strings.Join(ids, ","))
+
+	case "minbags":
+		for _, g := range games {
+			fmt.Printf("game %d: %s\n", g.ID, g.GetMinBag())
+		}
+
+	case "frontier":
+		if len(args) != 2 {
+			helper.ExitWithMessage("usage: frontier <k>")
+		}
+		k, err := strconv.Atoi(args[1])
+		helper.ExitOnError(err, "parse k")
+		for _, bag := range GetMinimalBagsForGameCount(games, k) {
+			fmt.Println(bag)
+		}
+
+	default:
+		flag.Usage()
+		helper.ExitWithMessage("unknown query %q", args[0])
+	}
+}
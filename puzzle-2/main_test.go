@@ -0,0 +1,98 @@
+package main
+
+import (
+	"aoc/helper"
+	"sort"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	games := parseGames(helper.ReadLines("example-1.txt"))
+	if sum := sumPossibleGameIDs(games, Bag{ColorRed: 12, ColorGreen: 13, ColorBlue: 14}); sum != 8 {
+		t.Errorf("part 1 is %d instead of 8", sum)
+	}
+	if sum := sumPowerOfMinBags(games); sum != 2286 {
+		t.Errorf("part 2 is %d instead of 2286", sum)
+	}
+}
+
+func TestMinimalBagsForGameCount(t *testing.T) {
+	games := parseGames(helper.ReadLines("example-1.txt"))
+	colors := GetColors(games)
+	// the next smaller candidate of a count is the largest minimal count of a game below it, or zero
+	nextSmaller := func(c Color, v int) int {
+		smaller := 0
+		for _, g := range games {
+			if m := g.GetMinBag()[c]; m < v && m > smaller {
+				smaller = m
+			}
+		}
+		return smaller
+	}
+
+	for k := 1; k <= len(games)+1; k++ {
+		frontier := GetMinimalBagsForGameCount(games, k)
+		if k > len(games) && len(frontier) > 0 {
+			t.Errorf("k=%d: %d bags admit more games than there are", k, len(frontier))
+		}
+		if k <= len(games) && len(frontier) == 0 {
+			t.Errorf("k=%d: no bag found", k)
+		}
+		seen := make(map[string]bool)
+		for _, bag := range frontier {
+			if seen[bag.String()] {
+				t.Errorf("k=%d: bag %s is returned twice", k, bag)
+			}
+			seen[bag.String()] = true
+			if count := len(GetPossibleGames(games, bag)); count < k {
+				t.Errorf("k=%d: bag %s only admits %d games", k, bag, count)
+			}
+			for _, c := range colors {
+				if bag[c] == 0 {
+					continue
+				}
+				smaller := make(Bag)
+				for c2, v := range bag {
+					smaller[c2] = v
+				}
+				smaller[c] = nextSmaller(c, bag[c])
+				if count := len(GetPossibleGames(games, smaller)); count >= k {
+					t.Errorf("k=%d: bag %s is not minimal, %s still admits %d games", k, bag, smaller, count)
+				}
+			}
+		}
+	}
+
+	// a single game needs its own minimal bag at the most
+	frontier := GetMinimalBagsForGameCount(games, 1)
+	got := make([]string, len(frontier))
+	for i, bag := range frontier {
+		got[i] = bag.String()
+	}
+	sort.Strings(got)
+	for _, g := range games {
+		minBag := g.GetMinBag()
+		covered := false
+		for _, bag := range frontier {
+			covered = covered || minBag.Contains(bag)
+		}
+		if !covered {
+			t.Errorf("minimal bag %s of game %d contains no bag of the frontier %v", minBag, g.ID, got)
+		}
+	}
+}
+
+func TestParseBag(t *testing.T) {
+	bag, err := ParseBag("red=12, green=13,blue=14")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if bag.String() != "blue=14,green=13,red=12" {
+		t.Errorf("bag is %s", bag)
+	}
+	for _, str := range []string{"red=abc", "red", "=3", "red=1,green"} {
+		if bag, err := ParseBag(str); err == nil {
+			t.Errorf("%q is parsed as %s", str, bag)
+		}
+	}
+}
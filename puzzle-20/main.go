@@ -4,20 +4,32 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/dot"
+	"errors"
+	"flag"
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 func main() {
+	dotFile := flag.String("dot", "", "write module network as DOT graph to file")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	system := ParseSystem(lines)
-	highCount, lowCount := system.CountPulsesForButtonPushes(1000)
+	if len(*dotFile) > 0 {
+		helper.ExitOnError(system.ToDot().WriteFile(*dotFile), "write dot file")
+	}
+	compiled, err := system.Compile()
+	helper.ExitOnError(err, "compile system")
+	highCount, lowCount := compiled.CountPulsesForButtonPushes(1000)
 	solution1 := highCount * lowCount
 	fmt.Println("-> part 1:", solution1)
 
-	system.Reset()
-	solution2 := system.CountButtonPushesForRXLow()
+	solution2, err := system.CountButtonPushesForRXLow()
+	helper.ExitOnError(err, "analyze rx")
 	fmt.Println("-> part 2:", solution2)
 }
 
@@ -55,7 +67,9 @@ func ParseSystem(lines []string) *System {
 }
 
 type System struct {
-	Modules map[string]Module
+	Modules   map[string]Module
+	Tracer    PulseTracer
+	PushCount int64
 }
 
 type Module interface {
@@ -184,10 +198,38 @@ type Pulse struct {
 	High     bool
 }
 
+func (p Pulse) String() string {
+	if p.High {
+		return p.From + " -high-> " + p.To
+	}
+	return p.From + " -low-> " + p.To
+}
+
+// PulseTracer receives every pulse processed by the system together with the number of the button push (starting at 1).
+type PulseTracer func(push int64, p Pulse)
+
+// FilterTrace only forwards pulses that are sent from or to one of the given modules.
+func FilterTrace(tracer PulseTracer, modules ...string) PulseTracer {
+	names := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		names[m] = true
+	}
+	return func(push int64, p Pulse) {
+		if names[p.From] || names[p.To] {
+			tracer(push, p)
+		}
+	}
+}
+
+func PrintTrace(push int64, p Pulse) {
+	fmt.Printf("[%d] %s\n", push, p)
+}
+
 func (s *System) SimulateSingleButtonPush() (int64, int64) {
 	if _, ok := s.Modules["broadcaster"]; !ok {
 		helper.ExitWithMessage("system has no broadcaster")
 	}
+	s.PushCount++
 	var lowCount, highCount int64
 	pulses := []Pulse{
 		{From: "button", To: "broadcaster", High: false},
@@ -196,11 +238,12 @@ func (s *System) SimulateSingleButtonPush() (int64, int64) {
 		p := pulses[0]
 		pulses = pulses[1:]
 
+		if s.Tracer != nil {
+			s.Tracer(s.PushCount, p)
+		}
 		if p.High {
-			//fmt.Println(p.From, "[high]", "->", p.To)
 			highCount++
 		} else {
-			//fmt.Println(p.From, "[low]", "->", p.To)
 			lowCount++
 		}
 
@@ -218,6 +261,7 @@ func (s *System) Reset() {
 	for _, m := range s.Modules {
 		m.Reset()
 	}
+	s.PushCount = 0
 }
 
 func (s *System) StateStr() string {
@@ -231,77 +275,406 @@ func (s *System) StateStr() string {
 	return str
 }
 
-func (s *System) CountButtonPushesForRXLow() int64 {
-	s.DetectLoopsForRX()
-	return 0
+func (s *System) CountButtonPushesForRXLow() (int64, error) {
+	feeder, err := s.FindSingleInput("rx")
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := s.Modules[feeder].(*ConjunctionModule); !ok {
+		return 0, fmt.Errorf("module %q feeding rx is not a conjunction", feeder)
+	}
+	cycles, err := s.AnalyzeConjunctionInputs(feeder, 100000)
+	if err != nil {
+		return 0, err
+	}
+	return CombineHighCycles(cycles)
+}
+
+// FindSingleInput returns the only module sending pulses to the given module.
+func (s *System) FindSingleInput(name string) (string, error) {
+	inputs := s.FindInputs(name)
+	if len(inputs) == 0 {
+		return "", fmt.Errorf("no module sends pulses to %q", name)
+	}
+	if len(inputs) > 1 {
+		return "", fmt.Errorf("multiple modules send pulses to %q: %s", name, strings.Join(inputs, ", "))
+	}
+	return inputs[0], nil
 }
 
-func (s *System) DetectLoopsForRX() {
-	mBroadcast := s.Modules["broadcaster"].(*BroadcastModule)
-	moduleToRX := s.FindModuleToRX()
-	mLoopEnd, ok := moduleToRX.(*ConjunctionModule)
+func (s *System) FindInputs(name string) []string {
+	inputs := make([]string, 0)
+	helper.IterateMapInKeyOrder(s.Modules, func(k string, m Module) {
+		for _, o := range m.Outputs() {
+			if o == name {
+				inputs = append(inputs, k)
+				break
+			}
+		}
+	})
+	return inputs
+}
+
+// HighCycle describes the button pushes at which a module sends a high pulse: Offset + n*Period for n >= 0.
+type HighCycle struct {
+	Module string
+	Offset int64
+	Period int64
+}
+
+// AnalyzeConjunctionInputs simulates the system from its initial state and detects for every input of the given conjunction
+// the periodic button pushes during which the input sends a high pulse to it.
+// Three observed hits with equal distance are required to accept a period.
+func (s *System) AnalyzeConjunctionInputs(name string, maxPushes int64) ([]HighCycle, error) {
+	conj, ok := s.Modules[name].(*ConjunctionModule)
 	if !ok {
-		helper.ExitWithMessage("module to rx is not ConjunctionModule")
+		return nil, fmt.Errorf("module %q is not a conjunction", name)
 	}
-	loopLengths := make([]int64, 0)
-	for _, m := range mBroadcast.Outputs() {
-		mod := s.Modules[m]
-		subSystem := s.BuildSubSystem(mod, mLoopEnd)
-		loopLength := subSystem.FindLoopLength()
-		loopLengths = append(loopLengths, int64(loopLength-1))
+	if len(conj.inputs) == 0 {
+		return nil, fmt.Errorf("conjunction %q has no inputs", name)
 	}
-	fmt.Println(helper.LeastCommonMultiple(loopLengths...) + 1)
+
+	hits := make(map[string][]int64, len(conj.inputs))
+	isComplete := func() bool {
+		for input := range conj.inputs {
+			if len(hits[input]) < 3 {
+				return false
+			}
+		}
+		return true
+	}
+
+	oldTracer := s.Tracer
+	defer func() { s.Tracer = oldTracer }()
+	s.Tracer = FilterTrace(func(push int64, p Pulse) {
+		if p.To == name && p.High {
+			h := hits[p.From]
+			if len(h) == 0 || h[len(h)-1] != push {
+				hits[p.From] = append(h, push)
+			}
+		}
+	}, name)
+
+	s.Reset()
+	for s.PushCount < maxPushes && !isComplete() {
+		s.SimulateSingleButtonPush()
+	}
+
+	cycles := make([]HighCycle, 0, len(conj.inputs))
+	var errs []string
+	helper.IterateMapInKeyOrder(conj.inputs, func(input string, _ bool) {
+		h := hits[input]
+		if len(h) < 3 {
+			errs = append(errs, fmt.Sprintf("input %q of %q went high only %d times within %d pushes", input, name, len(h), maxPushes))
+			return
+		}
+		if h[1]-h[0] != h[2]-h[1] {
+			errs = append(errs, fmt.Sprintf("input %q of %q went high at irregular pushes %d, %d, %d", input, name, h[0], h[1], h[2]))
+			return
+		}
+		cycles = append(cycles, HighCycle{Module: input, Offset: h[0], Period: h[1] - h[0]})
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("unexpected network structure: %s", strings.Join(errs, "; "))
+	}
+	return cycles, nil
+}
+
+// CombineHighCycles returns the first button push at which all cycles send a high pulse.
+func CombineHighCycles(cycles []HighCycle) (int64, error) {
+	residues := make([]int64, len(cycles))
+	moduli := make([]int64, len(cycles))
+	var minOffset int64
+	for i, c := range cycles {
+		residues[i] = c.Offset
+		moduli[i] = c.Period
+		minOffset = helper.Max(minOffset, c.Offset)
+	}
+	x, m, err := helper.SolveCongruences(residues, moduli)
+	if errors.Is(err, helper.ErrNoCongruenceSolution) {
+		return 0, fmt.Errorf("high cycles %v never align", cycles)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("first alignment of high cycles %v exceeds int64", cycles)
+	}
+	if x < minOffset {
+		x += ((minOffset - x + m - 1) / m) * m
+	}
+	return x, nil
+}
+
+type compiledKind uint8
+
+const (
+	kindSink compiledKind = iota
+	kindBroadcast
+	kindFlipFlop
+	kindConjunction
+)
+
+type compiledOutput struct {
+	To int32
+	// Slot is the bit of the sender in the memory word of a receiving conjunction.
+	Slot uint8
+}
+
+type compiledPulse struct {
+	From, To int32
+	Slot     uint8
+	High     bool
+}
+
+// CompiledSystem simulates a system with integer module IDs instead of names.
+// The state of flip-flop i is bit i of State, the memory of conjunction j is the word State[FlipFlopWords+j] with one bit per input.
+type CompiledSystem struct {
+	Names         []string
+	IDs           map[string]int
+	Broadcaster   int
+	State         helper.Bitset
+	FlipFlopWords int
+	Tracer        PulseTracer
+	PushCount     int64
+
+	kinds   []compiledKind
+	outputs [][]compiledOutput
+	// bits contains the bit index in State for flip-flops and the word index for conjunctions.
+	bits []int
+	// fullMasks contains the memory word of conjunctions with all inputs high.
+	fullMasks []uint64
+	queue     []compiledPulse
 }
 
-func (s *System) FindModuleToRX() Module {
-	var moduleToRX Module
+// Compile converts the system including its current state, conjunctions must not have more than 64 inputs.
+func (s *System) Compile() (*CompiledSystem, error) {
+	c := &CompiledSystem{IDs: make(map[string]int), Broadcaster: -1}
+	addModule := func(name string) {
+		if _, ok := c.IDs[name]; !ok {
+			c.IDs[name] = len(c.Names)
+			c.Names = append(c.Names, name)
+		}
+	}
+	helper.IterateMapInKeyOrder(s.Modules, func(name string, _ Module) { addModule(name) })
+	sinks := make(map[string]bool)
 	for _, m := range s.Modules {
-		for _, r := range m.Outputs() {
-			if r == "rx" {
-				if moduleToRX != nil {
-					helper.ExitWithMessage("multiple modules to rx found")
+		for _, o := range m.Outputs() {
+			if _, ok := s.Modules[o]; !ok {
+				sinks[o] = true
+			}
+		}
+	}
+	helper.IterateMapInKeyOrder(sinks, func(name string, _ bool) { addModule(name) })
+
+	c.kinds = make([]compiledKind, len(c.Names))
+	c.bits = make([]int, len(c.Names))
+	c.fullMasks = make([]uint64, len(c.Names))
+	c.outputs = make([][]compiledOutput, len(c.Names))
+	var flipFlopCount, conjunctionCount int
+	inputSlots := make(map[string]map[string]uint8)
+	for id, name := range c.Names {
+		switch m := s.Modules[name].(type) {
+		case *BroadcastModule:
+			c.kinds[id] = kindBroadcast
+		case *FlipFlopModule:
+			c.kinds[id] = kindFlipFlop
+			c.bits[id] = flipFlopCount
+			flipFlopCount++
+		case *ConjunctionModule:
+			if len(m.inputs) > 64 {
+				return nil, fmt.Errorf("conjunction %q has %d inputs, but at most 64 are supported", name, len(m.inputs))
+			}
+			c.kinds[id] = kindConjunction
+			c.bits[id] = conjunctionCount
+			conjunctionCount++
+			inputSlots[name] = make(map[string]uint8, len(m.inputs))
+			helper.IterateMapInKeyOrder(m.inputs, func(input string, _ bool) {
+				inputSlots[name][input] = uint8(len(inputSlots[name]))
+			})
+			c.fullMasks[id] = 1<<len(m.inputs) - 1
+		}
+	}
+	if id, ok := c.IDs["broadcaster"]; ok && c.kinds[id] == kindBroadcast {
+		c.Broadcaster = id
+	} else {
+		return nil, fmt.Errorf("system has no broadcaster")
+	}
+
+	c.FlipFlopWords = (flipFlopCount + 63) / 64
+	c.State = make(helper.Bitset, c.FlipFlopWords+conjunctionCount)
+	for id, name := range c.Names {
+		switch c.kinds[id] {
+		case kindConjunction:
+			c.bits[id] += c.FlipFlopWords
+			for input, high := range s.Modules[name].(*ConjunctionModule).inputs {
+				if high {
+					c.State[c.bits[id]] |= 1 << inputSlots[name][input]
 				}
-				moduleToRX = m
+			}
+		case kindFlipFlop:
+			c.State.SetTo(c.bits[id], s.Modules[name].(*FlipFlopModule).isOn)
+		}
+		if m, ok := s.Modules[name]; ok {
+			for _, o := range m.Outputs() {
+				c.outputs[id] = append(c.outputs[id], compiledOutput{To: int32(c.IDs[o]), Slot: inputSlots[o][name]})
 			}
 		}
 	}
-	if moduleToRX == nil {
-		helper.ExitWithMessage("no module to rx found")
+	c.queue = make([]compiledPulse, 64)
+	c.PushCount = s.PushCount
+	return c, nil
+}
+
+// Clone returns an independent copy of the system state, the network itself is shared.
+func (c *CompiledSystem) Clone() *CompiledSystem {
+	c2 := *c
+	c2.State = c.State.Clone()
+	c2.queue = make([]compiledPulse, len(c.queue))
+	return &c2
+}
+
+func (c *CompiledSystem) EqualState(other *CompiledSystem) bool {
+	return c.State.Equal(other.State)
+}
+
+func (c *CompiledSystem) Reset() {
+	c.State.Reset()
+	c.PushCount = 0
+}
+
+func (c *CompiledSystem) CountPulsesForButtonPushes(pushCount int64) (int64, int64) {
+	var lowCount, highCount int64
+	for i := int64(0); i < pushCount; i++ {
+		l, h := c.SimulateSingleButtonPush()
+		lowCount += l
+		highCount += h
 	}
-	return moduleToRX
+	return lowCount, highCount
 }
 
-func (s *System) BuildSubSystem(start, end Module) *System {
-	modules := make(map[string]Module, 0)
-	s.collectModules(modules, start, end)
-	modules["broadcaster"] = &BroadcastModule{
-		name:    "broadcaster",
-		outputs: []string{start.Name()},
+// SimulateSingleButtonPush processes all pulses in a ring buffer, which only grows if more pulses are pending than ever before.
+func (c *CompiledSystem) SimulateSingleButtonPush() (int64, int64) {
+	c.PushCount++
+	var lowCount, highCount int64
+	mask := len(c.queue) - 1
+	head, count := 0, 1
+	c.queue[0] = compiledPulse{From: -1, To: int32(c.Broadcaster)}
+	for count > 0 {
+		p := c.queue[head]
+		head = (head + 1) & mask
+		count--
+
+		if c.Tracer != nil {
+			from := "button"
+			if p.From >= 0 {
+				from = c.Names[p.From]
+			}
+			c.Tracer(c.PushCount, Pulse{From: from, To: c.Names[p.To], High: p.High})
+		}
+		if p.High {
+			highCount++
+		} else {
+			lowCount++
+		}
+
+		var outHigh bool
+		switch c.kinds[p.To] {
+		case kindBroadcast:
+			outHigh = p.High
+		case kindFlipFlop:
+			if p.High {
+				continue
+			}
+			bit := c.bits[p.To]
+			c.State[bit/64] ^= 1 << (bit % 64)
+			outHigh = c.State.Get(bit)
+		case kindConjunction:
+			word := &c.State[c.bits[p.To]]
+			if p.High {
+				*word |= 1 << p.Slot
+			} else {
+				*word &^= 1 << p.Slot
+			}
+			outHigh = *word != c.fullMasks[p.To]
+		default:
+			continue
+		}
+
+		outputs := c.outputs[p.To]
+		if count+len(outputs) > len(c.queue) {
+			c.growQueue(head, count, count+len(outputs))
+			head, mask = 0, len(c.queue)-1
+		}
+		for _, o := range outputs {
+			c.queue[(head+count)&mask] = compiledPulse{From: p.To, To: o.To, Slot: o.Slot, High: outHigh}
+			count++
+		}
 	}
-	return &System{Modules: modules}
+	return lowCount, highCount
 }
 
-func (s *System) collectModules(modules map[string]Module, start, end Module) {
-	if _, ok := modules[start.Name()]; ok {
-		return
+// growQueue moves the pending pulses to the start of a larger ring buffer, its size is always a power of two.
+func (c *CompiledSystem) growQueue(head, count, minSize int) {
+	size := len(c.queue)
+	for size < minSize {
+		size *= 2
+	}
+	queue := make([]compiledPulse, size)
+	for i := 0; i < count; i++ {
+		queue[i] = c.queue[(head+i)&(len(c.queue)-1)]
 	}
-	if start == end {
-		return
+	c.queue = queue
+}
+
+// FindLoopLength returns the number of pushes before the system state repeats and the index of the first repeated state.
+func (c *CompiledSystem) FindLoopLength() (int, int) {
+	type seenState struct {
+		State helper.Bitset
+		Index int
 	}
-	modules[start.Name()] = start
-	for _, m := range start.Outputs() {
-		mod := s.Modules[m]
-		s.collectModules(modules, mod, end)
+	seen := make(map[uint64][]seenState)
+	for i := 0; ; i++ {
+		hash := c.State.Hash()
+		for _, other := range seen[hash] {
+			if other.State.Equal(c.State) {
+				return i, other.Index
+			}
+		}
+		seen[hash] = append(seen[hash], seenState{State: c.State.Clone(), Index: i})
+		c.SimulateSingleButtonPush()
 	}
 }
 
-func (s *System) FindLoopLength() int {
+func (s *System) ToDot() *dot.Graph {
+	g := dot.NewGraph("modules", true)
+	g.AddNode("button", dot.Attributes{"shape": "doublecircle"})
+	helper.IterateMapInKeyOrder(s.Modules, func(name string, m Module) {
+		switch m.(type) {
+		case *BroadcastModule:
+			g.AddNode(name, dot.Attributes{"shape": "doubleoctagon"})
+		case *FlipFlopModule:
+			g.AddNode(name, dot.Attributes{"shape": "box", "label": "%" + name, "style": "filled", "fillcolor": "lightblue"})
+		case *ConjunctionModule:
+			g.AddNode(name, dot.Attributes{"shape": "invhouse", "label": "&" + name, "style": "filled", "fillcolor": "orange"})
+		}
+	})
+	g.AddEdge("button", "broadcaster", nil)
+	helper.IterateMapInKeyOrder(s.Modules, func(name string, m Module) {
+		for _, o := range m.Outputs() {
+			if !g.HasNode(o) {
+				g.AddNode(o, dot.Attributes{"shape": "doublecircle"})
+			}
+			g.AddEdge(name, o, nil)
+		}
+	})
+	return g
+}
+
+// FindLoopLength returns the number of pushes before the system state repeats and the index of the first repeated state.
+func (s *System) FindLoopLength() (int, int) {
 	stateIndices := make(map[string]int)
 	for i := 0; ; i++ {
 		stateStr := s.StateStr()
 		if loopStartIndex, ok := stateIndices[stateStr]; ok {
-			fmt.Println("known state at", loopStartIndex)
-			return i
+			return i, loopStartIndex
 		}
 		stateIndices[stateStr] = i
 		s.SimulateSingleButtonPush()
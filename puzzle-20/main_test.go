@@ -0,0 +1,116 @@
+package main
+
+import (
+	"aoc/helper"
+	"fmt"
+	"testing"
+)
+
+func compileFile(t *testing.T, file string) (*System, *CompiledSystem) {
+	t.Helper()
+	system := ParseSystem(helper.ReadNonEmptyLines(file))
+	compiled, err := system.Compile()
+	if err != nil {
+		t.Fatalf("compile %s: %s", file, err.Error())
+	}
+	return system, compiled
+}
+
+func TestExamples(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want int64
+	}{
+		{"example-1.txt", 32000000},
+		{"example-2.txt", 11687500},
+	} {
+		system, compiled := compileFile(t, tc.file)
+		if low, high := system.CountPulsesForButtonPushes(1000); low*high != tc.want {
+			t.Errorf("%s: interpreter result is %d instead of %d", tc.file, low*high, tc.want)
+		}
+		if low, high := compiled.CountPulsesForButtonPushes(1000); low*high != tc.want {
+			t.Errorf("%s: compiled result is %d instead of %d", tc.file, low*high, tc.want)
+		}
+	}
+}
+
+func TestCompiledMatchesInterpreter(t *testing.T) {
+	for _, file := range []string{"example-1.txt", "example-2.txt", "input.txt"} {
+		system, compiled := compileFile(t, file)
+		for i := 0; i < 1000; i++ {
+			l1, h1 := system.SimulateSingleButtonPush()
+			l2, h2 := compiled.SimulateSingleButtonPush()
+			if l1 != l2 || h1 != h2 {
+				t.Fatalf("%s: push %d sent %d low and %d high pulses instead of %d and %d", file, system.PushCount, l2, h2, l1, h1)
+			}
+			if err := compareState(compiled, system); err != nil {
+				t.Fatalf("%s: push %d: %s", file, system.PushCount, err.Error())
+			}
+		}
+	}
+}
+
+// compareState returns an error if the state of a module differs from the interpreted system.
+func compareState(c *CompiledSystem, s *System) error {
+	for id, name := range c.Names {
+		switch m := s.Modules[name].(type) {
+		case *FlipFlopModule:
+			if c.State.Get(c.bits[id]) != m.isOn {
+				return fmt.Errorf("flip-flop %q is %v instead of %v", name, c.State.Get(c.bits[id]), m.isOn)
+			}
+		case *ConjunctionModule:
+			for _, o := range s.FindInputs(name) {
+				high := c.State[c.bits[id]]&(1<<slotOf(c, c.IDs[o], id)) != 0
+				if high != m.inputs[o] {
+					return fmt.Errorf("conjunction %q remembers %v for input %q instead of %v", name, high, o, m.inputs[o])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func slotOf(c *CompiledSystem, from, to int) uint8 {
+	for _, o := range c.outputs[from] {
+		if int(o.To) == to {
+			return o.Slot
+		}
+	}
+	return 0
+}
+
+func TestCloneAndEqualState(t *testing.T) {
+	_, compiled := compileFile(t, "input.txt")
+	compiled.CountPulsesForButtonPushes(10)
+	clone := compiled.Clone()
+	if !clone.EqualState(compiled) {
+		t.Fatal("clone differs from original")
+	}
+	clone.SimulateSingleButtonPush()
+	if clone.EqualState(compiled) {
+		t.Error("push on clone did not change its state")
+	}
+	if compiled.PushCount != 10 {
+		t.Errorf("push on clone changed push count of original to %d", compiled.PushCount)
+	}
+	compiled.SimulateSingleButtonPush()
+	if !clone.EqualState(compiled) {
+		t.Error("clone and original differ after the same number of pushes")
+	}
+	compiled.Reset()
+	_, fresh := compileFile(t, "input.txt")
+	if !compiled.EqualState(fresh) {
+		t.Error("reset system differs from freshly compiled system")
+	}
+}
+
+func TestFindLoopLength(t *testing.T) {
+	for _, file := range []string{"example-1.txt", "example-2.txt"} {
+		system, compiled := compileFile(t, file)
+		length, start := compiled.FindLoopLength()
+		wantLength, wantStart := system.FindLoopLength()
+		if length != wantLength || start != wantStart {
+			t.Errorf("%s: loop of length %d starts at %d instead of length %d at %d", file, length, start, wantLength, wantStart)
+		}
+	}
+}
@@ -4,15 +4,19 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/render"
+	"flag"
 	"fmt"
-	"os"
-	"strings"
 )
 
 func main() {
+	showReachable := flag.Bool("show", false, "print reachable positions of every computed garden")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	garden := ParseGarden(lines)
+	garden.ShowReachable = *showReachable
 	/*solution1 := garden.CountPossiblePositionsFromStartPos(64, false, false)
 	fmt.Println("-> part 1:", solution1)*/
 
@@ -67,6 +71,7 @@ type Garden struct {
 	Width, Height int
 	Tiles         [][]rune
 	StartPos      helper.Point2D[int]
+	ShowReachable bool
 }
 
 func (g Garden) CountPossiblePositionsFromStartPos(steps int64, repeatX, repeatY bool) int64 {
@@ -120,25 +125,22 @@ func (g Garden) CountPossiblePositionsFromPos(startPos helper.Point2D[int], step
 			nextSteps = append(nextSteps, VisitKey{Pos: nextPos, RemainingSteps: p.RemainingSteps - 1})
 		}
 	}
-	tiles := make([][]rune, len(g.Tiles))
-	for y := 0; y < g.Height; y++ {
-		tiles[y] = make([]rune, len(g.Tiles[y]))
-		copy(tiles[y], g.Tiles[y])
-	}
 	var count int64
+	reachable := make([]helper.Point2D[int], 0)
 	for v := range visited {
 		if v.RemainingSteps == 0 {
 			count++
 			if v.Pos.X >= 0 && v.Pos.Y >= 0 && v.Pos.X < g.Width && v.Pos.Y < g.Height {
-				tiles[v.Pos.Y][v.Pos.X] = 'O'
+				reachable = append(reachable, v.Pos)
 			}
 		}
 	}
-	buf := make([]string, len(tiles))
-	for y := 0; y < g.Height; y++ {
-		buf[y] = string(tiles[y])
+	if g.ShowReachable {
+		canvas := render.NewCanvas(g.Tiles)
+		canvas.Overlay("reachable", render.Green, 'O', reachable)
+		canvas.Overlay("start", render.Yellow, 'S', []helper.Point2D[int]{startPos})
+		fmt.Println(canvas.ANSI())
 	}
-	os.WriteFile("out.txt", []byte(strings.Join(buf, "\n")), os.ModePerm)
 	return count
 }
 
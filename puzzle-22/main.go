@@ -4,9 +4,15 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/dot"
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -15,15 +21,37 @@ var (
 )
 
 func main() {
+	dotFile := flag.String("dot", "", "write brick support relations as DOT graph to file")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: puzzle-22 [flags] [query args...]")
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), "queries on the settled stack:")
+		fmt.Fprintln(flag.CommandLine.Output(), "  loadbearing        list all bricks that would let other bricks fall")
+		fmt.Fprintln(flag.CommandLine.Output(), "  remove <bricks>    list the bricks that fall if all given bricks are removed, e.g. 3,17")
+		fmt.Fprintln(flag.CommandLine.Output(), "  chain <brick>      list the support chain of a brick")
+		fmt.Fprintln(flag.CommandLine.Output(), "  stats [bricks]     print statistics of the given or all bricks")
+		fmt.Fprintln(flag.CommandLine.Output(), "  height             print the maximum height of the stack")
+		fmt.Fprintln(flag.CommandLine.Output(), "  export <file.obj>  write the stack as Wavefront OBJ file")
+	}
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	world := ParseWorld(lines)
 	world.SimulateToEnd()
+	if len(*dotFile) > 0 {
+		helper.ExitOnError(world.ToDot().WriteFile(*dotFile), "write dot file")
+	}
+	if flag.NArg() > 0 {
+		runQuery(world, flag.Args())
+		return
+	}
 	desintegratableBricks := world.GetDesintegratableBricks()
 	solution1 := len(desintegratableBricks)
 	fmt.Println("-> part 1:", solution1)
 
-	solution2 := world.ComputePart2()
+	supports := world.GetSupportGraph()
+	solution2 := supports.ComputePart2()
 	fmt.Println("-> part 2:", solution2)
 }
 
@@ -52,7 +80,8 @@ func ParseWorld(lines []string) *World {
 }
 
 type World struct {
-	Bricks []Brick
+	Bricks       []Brick
+	supportGraph *SupportGraph
 }
 
 type Brick struct {
@@ -77,6 +106,7 @@ func (w *World) SimulateToEnd() int {
 }
 
 func (w *World) MoveBricks() int {
+	w.supportGraph = nil
 	var movedCount int
 	for i := range w.Bricks {
 		if !w.Bricks[i].Resting && w.MoveBrick(i) {
@@ -177,17 +207,317 @@ func (w *World) GetSupportingBricks(index int) []int {
 	return supportingBricks
 }
 
-func (w *World) ComputePart2() int {
+// SupportGraph is the directed acyclic graph of support relations between settled bricks. The ground is a virtual node with index len(Bricks) that supports all bricks on the floor.
+type SupportGraph struct {
+	Ground int
+	// Supports contains the bricks resting directly on each node.
+	Supports [][]int
+	// SupportedBy contains the nodes that each brick rests on directly.
+	SupportedBy [][]int
+	// Dominators is the dominator tree rooted in the ground: a brick falls if and only if one of its dominators is removed.
+	Dominators *helper.DominatorTree
+	// Order lists all bricks from bottom to top, so every brick comes after the bricks it rests on.
+	Order []int
+}
+
+// GetSupportGraph returns the support graph of the current brick positions, it is only computed once until bricks are moved again.
+func (w *World) GetSupportGraph() *SupportGraph {
+	if w.supportGraph != nil {
+		return w.supportGraph
+	}
+	ground := len(w.Bricks)
+	g := &SupportGraph{
+		Ground:      ground,
+		Supports:    make([][]int, ground+1),
+		SupportedBy: make([][]int, ground+1),
+		Order:       make([]int, ground),
+	}
+	for i := range g.Order {
+		g.Order[i] = i
+	}
+	sort.SliceStable(g.Order, func(i, j int) bool { return w.Bricks[g.Order[i]].Min.Z < w.Bricks[g.Order[j]].Min.Z })
+
+	// settled bricks do not overlap, so going from bottom to top visits the bricks of every column in ascending order
+	type top struct {
+		Z, Brick int
+	}
+	tops := make(map[helper.Point2D[int]]top)
+	for _, i := range g.Order {
+		b := w.Bricks[i]
+		if b.Min.Z <= 1 {
+			g.Supports[ground] = append(g.Supports[ground], i)
+			g.SupportedBy[i] = append(g.SupportedBy[i], ground)
+		}
+		for x := b.Min.X; x <= b.Max.X; x++ {
+			for y := b.Min.Y; y <= b.Max.Y; y++ {
+				p := helper.Point2D[int]{X: x, Y: y}
+				if t, ok := tops[p]; ok && t.Z == b.Min.Z-1 && !containsBrick(g.SupportedBy[i], t.Brick) {
+					g.Supports[t.Brick] = append(g.Supports[t.Brick], i)
+					g.SupportedBy[i] = append(g.SupportedBy[i], t.Brick)
+				}
+				tops[p] = top{Z: b.Max.Z, Brick: i}
+			}
+		}
+	}
+	for i := range g.Supports {
+		sort.Ints(g.Supports[i])
+		sort.Ints(g.SupportedBy[i])
+	}
+	g.Dominators = helper.NewDominatorTree(ground+1, ground, func(node int) []int { return g.Supports[node] })
+	w.supportGraph = g
+	return g
+}
+
+func containsBrick(bricks []int, index int) bool {
+	for _, b := range bricks {
+		if b == index {
+			return true
+		}
+	}
+	return false
+}
+
+// FallCounts returns the number of other bricks that would fall if a brick is removed.
+func (g *SupportGraph) FallCounts() []int {
+	sizes := g.Dominators.SubtreeSizes()
+	counts := make([]int, g.Ground)
+	for i := range counts {
+		counts[i] = sizes[i] - 1
+	}
+	return counts
+}
+
+func (g *SupportGraph) ComputePart2() int {
 	var sum int
-	for i := range w.Bricks {
-		affectedBricks := map[int]bool{}
-		w.CountBricksThatWouldFall(i, affectedBricks)
-		count := len(affectedBricks) - 1
+	for _, count := range g.FallCounts() {
 		sum += count
 	}
 	return sum
 }
 
+// LoadBearingBricks returns all bricks that are the only support of at least one other brick.
+func (w *World) LoadBearingBricks() []int {
+	g := w.GetSupportGraph()
+	bricks := make([]int, 0)
+	for i := range w.Bricks {
+		if len(g.Dominators.Children[i]) > 0 {
+			bricks = append(bricks, i)
+		}
+	}
+	return bricks
+}
+
+// RemoveBricks returns all other bricks that would fall if the given bricks are removed at the same time, ordered from bottom to top.
+func (w *World) RemoveBricks(indices ...int) []int {
+	g := w.GetSupportGraph()
+	gone := make([]bool, len(w.Bricks)+1)
+	for _, i := range indices {
+		gone[i] = true
+	}
+	falling := make([]int, 0)
+	for _, i := range g.Order {
+		if gone[i] {
+			continue
+		}
+		supported := false
+		for _, s := range g.SupportedBy[i] {
+			if !gone[s] {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			gone[i] = true
+			falling = append(falling, i)
+		}
+	}
+	return falling
+}
+
+// SupportChain returns all bricks whose removal alone lets the given brick fall, from top to bottom.
+func (w *World) SupportChain(index int) []int {
+	g := w.GetSupportGraph()
+	chain := make([]int, 0)
+	for d := g.Dominators.Idom[index]; d >= 0 && d != g.Ground; d = g.Dominators.Idom[d] {
+		chain = append(chain, d)
+	}
+	return chain
+}
+
+// MaxHeight returns the highest z coordinate occupied by a brick.
+func (w *World) MaxHeight() int {
+	var height int
+	for _, b := range w.Bricks {
+		height = helper.Max(height, b.Max.Z)
+	}
+	return height
+}
+
+type BrickStats struct {
+	Index       int
+	Brick       Brick
+	Volume      int
+	Supports    []int
+	SupportedBy []int
+	// SupportChain contains all bricks whose removal alone lets this brick fall.
+	SupportChain []int
+	// FallCount is the number of other bricks that fall if this brick is removed.
+	FallCount int
+}
+
+// GetBrickStats collects the statistics of a brick, fallCounts are the results of FallCounts to share them between queries of multiple bricks.
+func (w *World) GetBrickStats(index int, fallCounts []int) BrickStats {
+	g := w.GetSupportGraph()
+	b := w.Bricks[index]
+	supportedBy := make([]int, 0, len(g.SupportedBy[index]))
+	for _, s := range g.SupportedBy[index] {
+		if s != g.Ground {
+			supportedBy = append(supportedBy, s)
+		}
+	}
+	return BrickStats{
+		Index:        index,
+		Brick:        b,
+		Volume:       (b.Max.X - b.Min.X + 1) * (b.Max.Y - b.Min.Y + 1) * (b.Max.Z - b.Min.Z + 1),
+		Supports:     g.Supports[index],
+		SupportedBy:  supportedBy,
+		SupportChain: w.SupportChain(index),
+		FallCount:    fallCounts[index],
+	}
+}
+
+func (s BrickStats) String() string {
+	b := s.Brick
+	return fmt.Sprintf("brick %d at %d,%d,%d~%d,%d,%d: volume %d, supports [%s], supported by [%s], support chain [%s], %d bricks fall if removed",
+		s.Index, b.Min.X, b.Min.Y, b.Min.Z, b.Max.X, b.Max.Y, b.Max.Z, s.Volume, formatBricks(s.Supports), formatBricks(s.SupportedBy), formatBricks(s.SupportChain), s.FallCount)
+}
+
+func formatBricks(indices []int) string {
+	strs := make([]string, len(indices))
+	for i, index := range indices {
+		strs[i] = strconv.Itoa(index)
+	}
+	return strings.Join(strs, ",")
+}
+
+func (w *World) parseBricks(str string) ([]int, error) {
+	indices := make([]int, 0)
+	for _, part := range strings.Split(str, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid brick %q", part)
+		}
+		if index < 0 || index >= len(w.Bricks) {
+			return nil, fmt.Errorf("brick %d does not exist", index)
+		}
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// WriteOBJ writes every brick as cuboid object in Wavefront OBJ format, with z pointing up.
+func (w *World) WriteOBJ(out io.Writer) error {
+	corners := [8][3]int{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1}}
+	// counter-clockwise seen from outside, vertex numbers relative to the first corner
+	faces := [6][4]int{{1, 4, 3, 2}, {5, 6, 7, 8}, {1, 2, 6, 5}, {2, 3, 7, 6}, {3, 4, 8, 7}, {4, 1, 5, 8}}
+	if _, err := fmt.Fprintf(out, "# %d bricks\n", len(w.Bricks)); err != nil {
+		return err
+	}
+	for i, b := range w.Bricks {
+		if _, err := fmt.Fprintf(out, "o brick-%d\n", i); err != nil {
+			return err
+		}
+		for _, c := range corners {
+			x, y, z := b.Min.X+c[0]*(b.Max.X-b.Min.X+1), b.Min.Y+c[1]*(b.Max.Y-b.Min.Y+1), b.Min.Z+c[2]*(b.Max.Z-b.Min.Z+1)
+			if _, err := fmt.Fprintf(out, "v %d %d %d\n", x, y, z); err != nil {
+				return err
+			}
+		}
+		for _, f := range faces {
+			offset := 8 * i
+			if _, err := fmt.Fprintf(out, "f %d %d %d %d\n", offset+f[0], offset+f[1], offset+f[2], offset+f[3]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *World) WriteOBJFile(file string) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteOBJ(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func runQuery(world *World, args []string) {
+	switch args[0] {
+	case "loadbearing":
+		fallCounts := world.GetSupportGraph().FallCounts()
+		loadBearing := world.LoadBearingBricks()
+		for _, i := range loadBearing {
+			fmt.Printf("brick %d: %d bricks fall if removed\n", i, fallCounts[i])
+		}
+		fmt.Printf("%d of %d bricks are load-bearing\n", len(loadBearing), len(world.Bricks))
+
+	case "remove":
+		if len(args) != 2 {
+			helper.ExitWithMessage("usage: remove <bricks>")
+		}
+		indices, err := world.parseBricks(args[1])
+		helper.ExitOnError(err, "parse bricks")
+		falling := world.RemoveBricks(indices...)
+		fmt.Printf("%d bricks fall: %s\n", len(falling), formatBricks(falling))
+
+	case "chain":
+		if len(args) != 2 {
+			helper.ExitWithMessage("usage: chain <brick>")
+		}
+		indices, err := world.parseBricks(args[1])
+		helper.ExitOnError(err, "parse brick")
+		fallCounts := world.GetSupportGraph().FallCounts()
+		for _, i := range indices {
+			stats := world.GetBrickStats(i, fallCounts)
+			fmt.Printf("brick %d rests on [%s] and falls if any of [%s] is removed\n", i, formatBricks(stats.SupportedBy), formatBricks(stats.SupportChain))
+		}
+
+	case "stats":
+		var indices []int
+		if len(args) > 1 {
+			var err error
+			indices, err = world.parseBricks(args[1])
+			helper.ExitOnError(err, "parse bricks")
+		} else {
+			indices = make([]int, len(world.Bricks))
+			for i := range indices {
+				indices[i] = i
+			}
+		}
+		fallCounts := world.GetSupportGraph().FallCounts()
+		for _, i := range indices {
+			fmt.Println(world.GetBrickStats(i, fallCounts))
+		}
+
+	case "height":
+		fmt.Println("maximum height:", world.MaxHeight())
+
+	case "export":
+		if len(args) != 2 {
+			helper.ExitWithMessage("usage: export <file.obj>")
+		}
+		helper.ExitOnError(world.WriteOBJFile(args[1]), "write OBJ file")
+
+	default:
+		flag.Usage()
+		helper.ExitWithMessage("unknown query %q", args[0])
+	}
+}
+
 func (w *World) CountBricksThatWouldFall(index int, affectedBricks map[int]bool) {
 	affectedBricks[index] = true
 
@@ -205,3 +535,30 @@ func (w *World) CountBricksThatWouldFall(index int, affectedBricks map[int]bool)
 		}
 	}
 }
+
+// ToDot returns the support relations of the settled bricks with edges pointing from a brick to the bricks resting on it.
+func (w *World) ToDot() *dot.Graph {
+	g := dot.NewGraph("bricks", true)
+	g.AddNode("ground", dot.Attributes{"shape": "box", "style": "filled", "fillcolor": "burlywood"})
+	for i, b := range w.Bricks {
+		attrs := dot.Attributes{"label": fmt.Sprintf("#%d\n%d,%d,%d~%d,%d,%d", i, b.Min.X, b.Min.Y, b.Min.Z, b.Max.X, b.Max.Y, b.Max.Z)}
+		if len(w.GetBricksOnlySupportedBy(i)) == 0 {
+			attrs["style"] = "filled"
+			attrs["fillcolor"] = "palegreen"
+		}
+		g.AddNode(brickNodeID(i), attrs)
+	}
+	for i, b := range w.Bricks {
+		if b.Min.Z <= 1 {
+			g.AddEdge("ground", brickNodeID(i), nil)
+		}
+		for _, j := range w.GetSupportedBricks(i) {
+			g.AddEdge(brickNodeID(i), brickNodeID(j), nil)
+		}
+	}
+	return g
+}
+
+func brickNodeID(index int) string {
+	return strconv.Itoa(index)
+}
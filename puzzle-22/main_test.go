@@ -0,0 +1,154 @@
+package main
+
+import (
+	"aoc/helper"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	world := ParseWorld(helper.ReadNonEmptyLines("example-1.txt"))
+	world.SimulateToEnd()
+	if count := len(world.GetDesintegratableBricks()); count != 5 {
+		t.Errorf("part 1 is %d instead of 5", count)
+	}
+	if sum := world.GetSupportGraph().ComputePart2(); sum != 7 {
+		t.Errorf("part 2 is %d instead of 7", sum)
+	}
+	checkSupportGraph(t, "example-1", world)
+}
+
+func TestRandomStacks(t *testing.T) {
+	rnd := rand.New(rand.NewSource(22))
+	for n := 0; n < 50; n++ {
+		checkSupportGraph(t, fmt.Sprintf("stack %d", n), randomWorld(rnd))
+	}
+}
+
+// randomWorld returns a settled stack of 5 to 44 bricks on a 5x5 area.
+func randomWorld(rnd *rand.Rand) *World {
+	brickCount := 5 + rnd.Intn(40)
+	lines := make([]string, 0, brickCount)
+	z := 1 + rnd.Intn(3)
+	for i := 0; i < brickCount; i++ {
+		x, y := rnd.Intn(5), rnd.Intn(5)
+		x2, y2, z2 := x, y, z
+		switch rnd.Intn(3) {
+		case 0:
+			x2 += rnd.Intn(5 - x)
+		case 1:
+			y2 += rnd.Intn(5 - y)
+		case 2:
+			z2 += rnd.Intn(3)
+		}
+		lines = append(lines, fmt.Sprintf("%d,%d,%d~%d,%d,%d", x, y, z, x2, y2, z2))
+		z = z2 + 1 + rnd.Intn(2)
+	}
+	// the order of lines must not matter
+	rnd.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+
+	world := ParseWorld(lines)
+	world.SimulateToEnd()
+	return world
+}
+
+// checkSupportGraph compares the support graph and the fall counts of the dominator tree with the collision checks and the recursive chain reaction.
+func checkSupportGraph(t *testing.T, name string, world *World) {
+	t.Helper()
+	g := world.GetSupportGraph()
+	for i := range world.Bricks {
+		if supported := world.GetSupportedBricks(i); len(supported) != len(g.Supports[i]) || len(supported) > 0 && !reflect.DeepEqual(g.Supports[i], supported) {
+			t.Errorf("%s: brick %d supports %v instead of %v", name, i, g.Supports[i], supported)
+		}
+	}
+	for i, count := range g.FallCounts() {
+		affectedBricks := map[int]bool{}
+		world.CountBricksThatWouldFall(i, affectedBricks)
+		if count != len(affectedBricks)-1 {
+			t.Errorf("%s: removing brick %d lets %d bricks fall instead of %d", name, i, count, len(affectedBricks)-1)
+		}
+	}
+}
+
+func TestQueries(t *testing.T) {
+	example := ParseWorld(helper.ReadNonEmptyLines("example-1.txt"))
+	example.SimulateToEnd()
+	worlds := []*World{example}
+	rnd := rand.New(rand.NewSource(49))
+	for n := 0; n < 20; n++ {
+		worlds = append(worlds, randomWorld(rnd))
+	}
+
+	for n, world := range worlds {
+		fallCounts := world.GetSupportGraph().FallCounts()
+		loadBearing := make([]int, 0)
+		for i, count := range fallCounts {
+			if falling := world.RemoveBricks(i); len(falling) != count {
+				t.Errorf("world %d: removing brick %d lets %d bricks fall instead of %d", n, i, len(falling), count)
+			}
+			if count > 0 {
+				loadBearing = append(loadBearing, i)
+			}
+			if stats := world.GetBrickStats(i, fallCounts); stats.FallCount != count {
+				t.Errorf("world %d: stats of brick %d have fall count %d instead of %d", n, i, stats.FallCount, count)
+			}
+		}
+		if got := world.LoadBearingBricks(); !reflect.DeepEqual(got, loadBearing) {
+			t.Errorf("world %d: load-bearing bricks are %v instead of %v", n, got, loadBearing)
+		}
+	}
+}
+
+func TestExampleQueries(t *testing.T) {
+	// A=0 supports B=1 and C=2, which both support D=3 and E=4, which both support F=5 supporting G=6
+	world := ParseWorld(helper.ReadNonEmptyLines("example-1.txt"))
+	world.SimulateToEnd()
+	for _, tc := range []struct {
+		removed, want []int
+	}{
+		{[]int{1, 2}, []int{3, 4, 5, 6}},
+		{[]int{3, 4}, []int{5, 6}},
+		{[]int{1, 3}, []int{}},
+		{[]int{0}, []int{1, 2, 3, 4, 5, 6}},
+	} {
+		if falling := world.RemoveBricks(tc.removed...); !reflect.DeepEqual(falling, tc.want) {
+			t.Errorf("removing %v lets %v fall instead of %v", tc.removed, falling, tc.want)
+		}
+	}
+	if chain := world.SupportChain(6); !reflect.DeepEqual(chain, []int{5, 0}) {
+		t.Errorf("support chain of G is %v instead of [5 0]", chain)
+	}
+	if height := world.MaxHeight(); height != 6 {
+		t.Errorf("maximum height is %d instead of 6", height)
+	}
+}
+
+func TestWriteOBJ(t *testing.T) {
+	world := ParseWorld(helper.ReadNonEmptyLines("example-1.txt"))
+	world.SimulateToEnd()
+	var sb strings.Builder
+	if err := world.WriteOBJ(&sb); err != nil {
+		t.Fatal(err.Error())
+	}
+	counts := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		fields := strings.Fields(line)
+		counts[fields[0]]++
+		if fields[0] == "f" {
+			// faces may only refer to vertices that are already defined
+			for _, f := range fields[1:] {
+				if index, err := strconv.Atoi(f); err != nil || index < 1 || index > counts["v"] {
+					t.Errorf("face %q refers to undefined vertex %s", line, f)
+				}
+			}
+		}
+	}
+	want := map[string]int{"#": 1, "o": 7, "v": 7 * 8, "f": 7 * 6}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("OBJ file contains %v instead of %v lines", counts, want)
+	}
+}
@@ -4,13 +4,21 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/dot"
+	"flag"
 	"fmt"
 )
 
 func main() {
+	dotFile := flag.String("dot", "", "write component network as DOT graph to file")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("example-1.txt")
 
 	network := ParseNetwork(lines)
+	if len(*dotFile) > 0 {
+		helper.ExitOnError(network.ToDot().WriteFile(*dotFile), "write dot file")
+	}
 	fmt.Println(network)
 	solution1 := 0
 	fmt.Println("-> part 1:", solution1)
@@ -44,3 +52,20 @@ func ParseNetwork(lines []string) *Network {
 type Network struct {
 	Components map[string]*map[string]bool
 }
+
+func (n *Network) ToDot() *dot.Graph {
+	g := dot.NewGraph("components", false)
+	g.NodeStyle = dot.Attributes{"shape": "ellipse"}
+	type link struct{ A, B string }
+	seenLinks := make(map[link]bool)
+	helper.IterateMapInKeyOrder(n.Components, func(from string, links *map[string]bool) {
+		helper.IterateMapInKeyOrder(*links, func(to string, _ bool) {
+			l := link{A: helper.Min(from, to), B: helper.Max(from, to)}
+			if !seenLinks[l] {
+				seenLinks[l] = true
+				g.AddEdge(l.A, l.B, nil)
+			}
+		})
+	})
+	return g
+}
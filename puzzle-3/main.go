@@ -15,8 +15,10 @@ func main() {
 	partNumbers := ExtractPartNumbers(lines)
 	solution1 := SumPartNumbers(partNumbers)
 
-	gears := FindGears(lines, partNumbers)
-	solution2 := SumGears(gears)
+	schematic := NewSchematic(lines, partNumbers)
+	gearRule := DefaultGearRule()
+	gears := schematic.FindGears(gearRule)
+	solution2 := SumGears(gears, gearRule)
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
@@ -95,49 +97,102 @@ func SumPartNumbers(partNumbers []PartNumber) int {
 	return sum
 }
 
-func FindGears(lines []string, partNumbers []PartNumber) map[string][]int {
-	gears := make(map[string][]int, 0)
-	for y := 0; y < len(lines); y++ {
-		for x := 0; x < len(lines[y]); x++ {
-			if lines[y][x] == '*' {
-				gear := FindGearNumbers(partNumbers, x, y)
-				if len(gear) >= 2 {
-					gears[fmt.Sprintf("%d,%d", x, y)] = gear
-				}
+// Schematic labels every cell with the index of the part number occupying it, or -1 for all other cells.
+type Schematic struct {
+	Lines       []string
+	PartNumbers []PartNumber
+	Cells       [][]int
+}
+
+func NewSchematic(lines []string, partNumbers []PartNumber) *Schematic {
+	cells := make([][]int, len(lines))
+	for y := range lines {
+		cells[y] = make([]int, len(lines[y]))
+		for x := range cells[y] {
+			cells[y][x] = -1
+		}
+	}
+	for i, num := range partNumbers {
+		for x := num.X; x < num.X+num.Width; x++ {
+			cells[num.Y][x] = i
+		}
+	}
+	return &Schematic{Lines: lines, PartNumbers: partNumbers, Cells: cells}
+}
+
+// AdjacentPartNumbers returns the indices of all distinct part numbers next to the given cell.
+func (s *Schematic) AdjacentPartNumbers(x, y int) []int {
+	indices := make([]int, 0, 6)
+	for ny := y - 1; ny <= y+1; ny++ {
+		if ny < 0 || ny >= len(s.Cells) {
+			continue
+		}
+		for nx := x - 1; nx <= x+1; nx++ {
+			if nx < 0 || nx >= len(s.Cells[ny]) || (nx == x && ny == y) {
+				continue
+			}
+			if index := s.Cells[ny][nx]; index >= 0 && !containsInt(indices, index) {
+				indices = append(indices, index)
 			}
 		}
 	}
-	return gears
+	return indices
 }
 
-func FindGearNumbers(partNumbers []PartNumber, x, y int) []int {
-	gear := make([]int, 0)
-	for _, num := range partNumbers {
-		if BoxesOverlap(x-1, y-1, 3, 3, num.X, num.Y, num.Width, 1) {
-			gear = append(gear, num.Val)
+func containsInt(values []int, val int) bool {
+	for _, v := range values {
+		if v == val {
+			return true
 		}
 	}
-	return gear
+	return false
 }
 
-func BoxesOverlap(x1, y1, w1, h1, x2, y2, w2, h2 int) bool {
-	if x1+w1-1 < x2 || x1 > x2+w2-1 {
-		return false
+type GearRule struct {
+	Symbol byte
+	// NeighbourCount is the exact number of adjacent part numbers, any number of at least one is accepted for 0.
+	NeighbourCount int
+	Aggregate      func(values []int) int
+}
+
+func DefaultGearRule() GearRule {
+	return GearRule{Symbol: '*', NeighbourCount: 2, Aggregate: Product}
+}
+
+func Product(values []int) int {
+	product := 1
+	for _, v := range values {
+		product *= v
 	}
-	if y1+h1-1 < y2 || y1 > y2+h2-1 {
-		return false
+	return product
+}
+
+// FindGears returns the values of adjacent part numbers for every symbol that matches the gear rule.
+func (s *Schematic) FindGears(rule GearRule) map[helper.Point2D[int]][]int {
+	gears := make(map[helper.Point2D[int]][]int)
+	for y := range s.Lines {
+		for x := 0; x < len(s.Lines[y]); x++ {
+			if s.Lines[y][x] != rule.Symbol {
+				continue
+			}
+			indices := s.AdjacentPartNumbers(x, y)
+			if len(indices) == 0 || (rule.NeighbourCount > 0 && len(indices) != rule.NeighbourCount) {
+				continue
+			}
+			values := make([]int, len(indices))
+			for i, index := range indices {
+				values[i] = s.PartNumbers[index].Val
+			}
+			gears[helper.Point2D[int]{X: x, Y: y}] = values
+		}
 	}
-	return true
+	return gears
 }
 
-func SumGears(gears map[string][]int) int {
+func SumGears(gears map[helper.Point2D[int]][]int, rule GearRule) int {
 	var sum int
 	for _, g := range gears {
-		gearRatio := 1
-		for _, v := range g {
-			gearRatio *= v
-		}
-		sum += gearRatio
+		sum += rule.Aggregate(g)
 	}
 	return sum
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"aoc/helper"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func sumValues(values []int) int {
+	var sum int
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func TestExample(t *testing.T) {
+	lines := helper.ReadLines("example-1.txt")
+	partNumbers := ExtractPartNumbers(lines)
+	if sum := SumPartNumbers(partNumbers); sum != 4361 {
+		t.Errorf("part 1 is %d instead of 4361", sum)
+	}
+
+	schematic := NewSchematic(lines, partNumbers)
+	rule := DefaultGearRule()
+	gears := schematic.FindGears(rule)
+	want := map[helper.Point2D[int]][]int{{X: 3, Y: 1}: {35, 467}, {X: 5, Y: 8}: {598, 755}}
+	if !reflect.DeepEqual(sortedGears(gears), want) {
+		t.Errorf("gears are %v instead of %v", gears, want)
+	}
+	if sum := SumGears(gears, rule); sum != 467835 {
+		t.Errorf("part 2 is %d instead of 467835", sum)
+	}
+}
+
+func TestCustomGearRules(t *testing.T) {
+	lines := helper.ReadLines("example-1.txt")
+	schematic := NewSchematic(lines, ExtractPartNumbers(lines))
+	for _, tc := range []struct {
+		name  string
+		rule  GearRule
+		gears map[helper.Point2D[int]][]int
+		sum   int
+	}{
+		{"single neighbour", GearRule{Symbol: '*', NeighbourCount: 1, Aggregate: sumValues},
+			map[helper.Point2D[int]][]int{{X: 3, Y: 4}: {617}}, 617},
+		{"any neighbour count", GearRule{Symbol: '*', NeighbourCount: 0, Aggregate: sumValues},
+			map[helper.Point2D[int]][]int{{X: 3, Y: 1}: {35, 467}, {X: 3, Y: 4}: {617}, {X: 5, Y: 8}: {598, 755}}, 2472},
+		{"other symbol", GearRule{Symbol: '#', NeighbourCount: 1, Aggregate: Product},
+			map[helper.Point2D[int]][]int{{X: 6, Y: 3}: {633}}, 633},
+		{"three neighbours", GearRule{Symbol: '*', NeighbourCount: 3, Aggregate: Product},
+			map[helper.Point2D[int]][]int{}, 0},
+	} {
+		gears := schematic.FindGears(tc.rule)
+		if !reflect.DeepEqual(sortedGears(gears), tc.gears) {
+			t.Errorf("%s: gears are %v instead of %v", tc.name, gears, tc.gears)
+		}
+		if sum := SumGears(gears, tc.rule); sum != tc.sum {
+			t.Errorf("%s: sum is %d instead of %d", tc.name, sum, tc.sum)
+		}
+	}
+}
+
+func TestAdjacentPartNumbers(t *testing.T) {
+	// the same part number touches the symbol with several cells, but is only reported once
+	lines := []string{"123", ".*.", "4.5"}
+	schematic := NewSchematic(lines, ExtractPartNumbers(lines))
+	values := make([]int, 0)
+	for _, index := range schematic.AdjacentPartNumbers(1, 1) {
+		values = append(values, schematic.PartNumbers[index].Val)
+	}
+	sort.Ints(values)
+	if !reflect.DeepEqual(values, []int{4, 5, 123}) {
+		t.Errorf("adjacent part numbers are %v instead of [4 5 123]", values)
+	}
+}
+
+func sortedGears(gears map[helper.Point2D[int]][]int) map[helper.Point2D[int]][]int {
+	sorted := make(map[helper.Point2D[int]][]int, len(gears))
+	for p, values := range gears {
+		sorted[p] = append([]int{}, values...)
+		sort.Ints(sorted[p])
+	}
+	return sorted
+}
@@ -4,53 +4,66 @@ package main
 
 import (
 	"aoc/helper"
+	"bufio"
 	"fmt"
+	"io"
 	"math"
+	"math/big"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
 func main() {
-	lines := helper.ReadLines("input.txt")
+	f, err := os.Open("input.txt")
+	helper.ExitOnError(err, "open input")
+	defer f.Close()
 
-	scratchCards := ParseScratchCards(lines)
-	solution1 := SumScratchCardPoints(scratchCards)
-
-	EvalScratchCardRules(scratchCards)
-	solution2 := CountScratchCards(scratchCards)
+	solution1, solution2, err := EvalScratchCards(f)
+	helper.ExitOnError(err, "evaluate scratch cards")
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
 }
 
 type ScratchCard struct {
-	Count          int
-	WinningNumbers map[int]struct{}
-	HavingNumbers  []int
+	ID             int
+	WinningNumbers helper.Bitset
+	HavingNumbers  helper.Bitset
 }
 
 var patternScratchCard = regexp.MustCompile(`^Card\s+(\d+):\s*([\d\s]+)\s*\|\s*([\d\s]+)\s*$`)
 
-func ParseScratchCards(lines []string) []ScratchCard {
-	scratchCards := make([]ScratchCard, 0)
-	for _, line := range lines {
-		m := patternScratchCard.FindStringSubmatch(line)
-		if len(m) == 4 {
-			winningNumbers := ParseSpaceSeparatedInts(m[2])
-			winningNumbersMap := make(map[int]struct{}, len(winningNumbers))
-			for _, n := range winningNumbers {
-				winningNumbersMap[n] = struct{}{}
-			}
-			havingNumbers := ParseSpaceSeparatedInts(m[3])
-			scratchCards = append(scratchCards, ScratchCard{
-				Count:          1,
-				WinningNumbers: winningNumbersMap,
-				HavingNumbers:  havingNumbers,
-			})
+func ParseScratchCard(line string) (ScratchCard, error) {
+	m := patternScratchCard.FindStringSubmatch(line)
+	if len(m) != 4 {
+		return ScratchCard{}, fmt.Errorf("malformed scratch card %q", line)
+	}
+	var sc ScratchCard
+	var err error
+	sc.ID, _ = strconv.Atoi(m[1])
+	if sc.WinningNumbers, err = NewNumberSet(ParseSpaceSeparatedInts(m[2])); err != nil {
+		return ScratchCard{}, fmt.Errorf("card %d: %s", sc.ID, err.Error())
+	}
+	if sc.HavingNumbers, err = NewNumberSet(ParseSpaceSeparatedInts(m[3])); err != nil {
+		return ScratchCard{}, fmt.Errorf("card %d: %s", sc.ID, err.Error())
+	}
+	return sc, nil
+}
+
+// MaxNumber is the largest number on a scratch card, all number sets have the same fixed width.
+const MaxNumber = 127
+
+func NewNumberSet(numbers []int) (helper.Bitset, error) {
+	set := helper.NewBitset(MaxNumber + 1)
+	for _, n := range numbers {
+		if n < 0 || n > MaxNumber {
+			return nil, fmt.Errorf("number %d out of range [0, %d]", n, MaxNumber)
 		}
+		set.Set(n)
 	}
-	return scratchCards
+	return set, nil
 }
 
 func ParseSpaceSeparatedInts(str string) []int {
@@ -65,48 +78,106 @@ func ParseSpaceSeparatedInts(str string) []int {
 	return ints
 }
 
-func (sc ScratchCard) Points() int {
+func (sc ScratchCard) MatchCount() int {
+	return sc.WinningNumbers.CountCommon(sc.HavingNumbers)
+}
+
+func (sc ScratchCard) Points() Count {
 	matchCount := sc.MatchCount()
 	if matchCount == 0 {
-		return 0
+		return Count{}
 	}
-	return int(math.Pow(2, float64(matchCount-1)))
+	return PowerOfTwo(matchCount - 1)
 }
 
-func (sc ScratchCard) MatchCount() int {
-	count := 0
-	for _, v := range sc.HavingNumbers {
-		if _, ok := sc.WinningNumbers[v]; ok {
-			count++
-		}
+// ScratchCardEvaluator processes scratch cards one by one and only remembers the copies won for upcoming cards.
+type ScratchCardEvaluator struct {
+	Points     Count
+	CardCount  Count
+	upcoming   []Count
+	nextOffset int
+}
+
+func (e *ScratchCardEvaluator) Add(sc ScratchCard) {
+	e.Points = e.Points.Add(sc.Points())
+
+	copies := Count{small: 1}
+	if len(e.upcoming) > 0 {
+		copies = copies.Add(e.upcoming[e.nextOffset])
+		e.upcoming[e.nextOffset] = Count{}
+		e.nextOffset = (e.nextOffset + 1) % len(e.upcoming)
+	}
+	e.CardCount = e.CardCount.Add(copies)
+
+	matchCount := sc.MatchCount()
+	if matchCount > len(e.upcoming) {
+		e.growWindow(matchCount)
+	}
+	for i := 0; i < matchCount; i++ {
+		index := (e.nextOffset + i) % len(e.upcoming)
+		e.upcoming[index] = e.upcoming[index].Add(copies)
 	}
-	return count
 }
 
-func SumScratchCardPoints(scratchCards []ScratchCard) int {
-	sum := 0
-	for _, sc := range scratchCards {
-		sum += sc.Points()
+// growWindow resizes the ring buffer of upcoming copies and moves the next card to index 0.
+func (e *ScratchCardEvaluator) growWindow(size int) {
+	upcoming := make([]Count, size)
+	for i := range e.upcoming {
+		upcoming[i] = e.upcoming[(e.nextOffset+i)%len(e.upcoming)]
 	}
-	return sum
+	e.upcoming = upcoming
+	e.nextOffset = 0
 }
 
-func EvalScratchCardRules(scratchCards []ScratchCard) {
-	for i := range scratchCards {
-		matchCount := scratchCards[i].MatchCount()
-		for j := 0; j < matchCount; j++ {
-			index := i + 1 + j
-			if index < len(scratchCards) {
-				scratchCards[index].Count += scratchCards[i].Count
-			}
+// EvalScratchCards returns the sum of points and the total number of cards after applying the copy rules.
+// Copies won for cards after the last one are discarded.
+func EvalScratchCards(r io.Reader) (Count, Count, error) {
+	var e ScratchCardEvaluator
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.Trim(scanner.Text(), "\r")
+		if len(line) == 0 {
+			continue
+		}
+		sc, err := ParseScratchCard(line)
+		if err != nil {
+			return Count{}, Count{}, err
 		}
+		e.Add(sc)
+	}
+	return e.Points, e.CardCount, scanner.Err()
+}
+
+// Count is an exact non-negative integer that switches to big.Int as soon as it exceeds int64.
+type Count struct {
+	small int64
+	big   *big.Int
+}
+
+func PowerOfTwo(exp int) Count {
+	if exp < 63 {
+		return Count{small: 1 << exp}
+	}
+	return Count{big: new(big.Int).Lsh(big.NewInt(1), uint(exp))}
+}
+
+func (c Count) Add(other Count) Count {
+	if c.big == nil && other.big == nil && c.small <= math.MaxInt64-other.small {
+		return Count{small: c.small + other.small}
+	}
+	return Count{big: new(big.Int).Add(c.Big(), other.Big())}
+}
+
+func (c Count) Big() *big.Int {
+	if c.big != nil {
+		return c.big
 	}
+	return big.NewInt(c.small)
 }
 
-func CountScratchCards(scratchCards []ScratchCard) int {
-	sum := 0
-	for _, sc := range scratchCards {
-		sum += sc.Count
+func (c Count) String() string {
+	if c.big != nil {
+		return c.big.String()
 	}
-	return sum
+	return strconv.FormatInt(c.small, 10)
 }
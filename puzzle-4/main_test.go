@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	f, err := os.Open("example-1.txt")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer f.Close()
+	points, cards, err := EvalScratchCards(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if points.String() != "13" {
+		t.Errorf("points are %s instead of 13", points)
+	}
+	if cards.String() != "30" {
+		t.Errorf("card count is %s instead of 30", cards)
+	}
+}
+
+func TestNumberRange(t *testing.T) {
+	for _, line := range []string{
+		"Card 1: 1 2 128 | 1 2 3",
+		"Card 2: 1 2 3 | 9999999999",
+		"Card 3: 1 2 3 | 99999999999999999999",
+	} {
+		if _, err := ParseScratchCard(line); err == nil {
+			t.Errorf("%q is accepted", line)
+		}
+	}
+	if _, err := ParseScratchCard(fmt.Sprintf("Card 4: 0 %d | %d", MaxNumber, MaxNumber)); err != nil {
+		t.Errorf("numbers 0 and %d are rejected: %s", MaxNumber, err.Error())
+	}
+}
+
+// scratchCardLine returns a card with the given number of matches.
+func scratchCardLine(id, matchCount int) string {
+	winning := make([]string, 0)
+	having := []string{"99"}
+	for n := 1; n <= matchCount; n++ {
+		winning = append(winning, fmt.Sprint(n))
+		having = append(having, fmt.Sprint(n))
+	}
+	return fmt.Sprintf("Card %d: %s | %s", id, strings.Join(winning, " "), strings.Join(having, " "))
+}
+
+func TestCopyWindow(t *testing.T) {
+	// the window grows several times and wraps around in between, copies after the last card are discarded
+	matchCounts := []int{1, 3, 0, 2, 5, 1, 0, 0, 4, 2, 7, 1, 3}
+	lines := make([]string, len(matchCounts))
+	for i, m := range matchCounts {
+		lines[i] = scratchCardLine(i+1, m)
+	}
+	points, cards, err := EvalScratchCards(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	copies := make([]int64, len(matchCounts))
+	var wantPoints, wantCards int64
+	for i, m := range matchCounts {
+		copies[i]++
+		wantCards += copies[i]
+		if m > 0 {
+			wantPoints += 1 << (m - 1)
+		}
+		for j := i + 1; j <= i+m && j < len(copies); j++ {
+			copies[j] += copies[i]
+		}
+	}
+	if points.String() != fmt.Sprint(wantPoints) {
+		t.Errorf("points are %s instead of %d", points, wantPoints)
+	}
+	if cards.String() != fmt.Sprint(wantCards) {
+		t.Errorf("card count is %s instead of %d", cards, wantCards)
+	}
+}
+
+func TestCountOverflow(t *testing.T) {
+	// every card matches all following cards, so the number of copies doubles with every card
+	const cardCount = 80
+	lines := make([]string, cardCount)
+	for i := range lines {
+		lines[i] = scratchCardLine(i+1, cardCount-i-1)
+	}
+	points, cards, err := EvalScratchCards(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	one := big.NewInt(1)
+	wantCards := new(big.Int).Sub(new(big.Int).Lsh(one, cardCount), one)
+	if cards.Big().Cmp(wantCards) != 0 {
+		t.Errorf("card count is %s instead of %s", cards, wantCards)
+	}
+	// 2^78 + 2^77 + ... + 2^0
+	wantPoints := new(big.Int).Sub(new(big.Int).Lsh(one, cardCount-1), one)
+	if points.Big().Cmp(wantPoints) != 0 {
+		t.Errorf("points are %s instead of %s", points, wantPoints)
+	}
+}
@@ -4,24 +4,34 @@ package main
 
 import (
 	"aoc/helper"
+	"flag"
 	"fmt"
+	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 func main() {
+	location := flag.Int("location", -1, "print all seed ranges that land in the given location")
+	flag.Parse()
+
 	lines := helper.ReadLines("input.txt")
 
 	seedRanges2, mapChain := ParseInput(lines)
 	seedRanges1 := GetSeedRangesPart1(seedRanges2)
-	locationRanges1 := mapChain.MapSeedRangesToLocationRanges(seedRanges1)
-	locationRanges2 := mapChain.MapSeedRangesToLocationRanges(seedRanges2)
-	solution1 := GetLowestRangeValue(locationRanges1)
-	solution2 := GetLowestRangeValue(locationRanges2)
+	seedToLocation := mapChain.Compile()
+	solution1 := seedToLocation.LowestValue(seedRanges1)
+	solution2 := seedToLocation.LowestValue(seedRanges2)
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
+
+	if *location >= 0 {
+		seeds := seedToLocation.Invert().MapRanges([]Range{{First: *location, Last: *location}})
+		fmt.Println("seeds for location", *location, "->", seeds)
+	}
 }
 
 type Range struct {
@@ -99,58 +109,151 @@ func ParseMapChain(lines []string) MapChain {
 	return MapChain{MappingGroups: mappingGroups}
 }
 
-func (mc MapChain) MapSeedRangesToLocationRanges(seedRanges []Range) []Range {
-	ranges := seedRanges
+// PiecewiseMapping maps every value inside a piece by adding the offset of the piece.
+// Pieces are ordered by First. Compiled mappings cover all non-negative values without overlaps,
+// inverted mappings might contain overlapping pieces and gaps without any preimage.
+type PiecewiseMapping struct {
+	Pieces []Piece
+}
+
+type Piece struct {
+	First, Last int
+	Offset      int
+}
+
+// Compile combines all mapping groups into a single mapping from the first source to the last destination.
+func (mc MapChain) Compile() PiecewiseMapping {
+	result := PiecewiseMapping{Pieces: []Piece{{First: 0, Last: math.MaxInt, Offset: 0}}}
 	for _, mg := range mc.MappingGroups {
-		ranges = mg.MapRanges(ranges)
+		result = result.Then(mg.Compile())
 	}
-	return ranges
+	return result
 }
 
-func (mg MappingGroup) MapRanges(srcRanges []Range) []Range {
-	ranges := srcRanges
-	dstRanges := make([]Range, 0)
-	for _, m := range mg.Mappings {
-		if len(ranges) == 0 {
-			break
+// Compile returns the mapping of the group with identity mapped pieces for all values not covered by a mapping.
+func (mg MappingGroup) Compile() PiecewiseMapping {
+	mappings := make([]Mapping, len(mg.Mappings))
+	copy(mappings, mg.Mappings)
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].SrcStart < mappings[j].SrcStart
+	})
+
+	pieces := make([]Piece, 0, 2*len(mappings)+1)
+	next := 0
+	for _, m := range mappings {
+		if m.SrcStart > next {
+			pieces = append(pieces, Piece{First: next, Last: m.SrcStart - 1, Offset: 0})
+		}
+		first := helper.Max(m.SrcStart, next)
+		last := m.SrcStart + m.Range - 1
+		if last >= first {
+			pieces = append(pieces, Piece{First: first, Last: last, Offset: m.DstStart - m.SrcStart})
+			next = last + 1
 		}
+	}
+	pieces = append(pieces, Piece{First: next, Last: math.MaxInt, Offset: 0})
+	return PiecewiseMapping{Pieces: pieces}.merged()
+}
 
-		mapped, remainder := m.MapRanges(ranges)
-		dstRanges = append(dstRanges, mapped...)
-		ranges = remainder
+// Then returns the mapping that applies pm first and other afterwards.
+func (pm PiecewiseMapping) Then(other PiecewiseMapping) PiecewiseMapping {
+	pieces := make([]Piece, 0, len(pm.Pieces)+len(other.Pieces))
+	for _, p := range pm.Pieces {
+		image := Range{First: addClamped(p.First, p.Offset), Last: addClamped(p.Last, p.Offset)}
+		for _, o := range other.Pieces {
+			first := helper.Max(image.First, o.First)
+			last := helper.Min(image.Last, o.Last)
+			if first <= last {
+				pieces = append(pieces, Piece{First: first - p.Offset, Last: last - p.Offset, Offset: p.Offset + o.Offset})
+			}
+		}
 	}
-	dstRanges = append(dstRanges, ranges...)
-	return dstRanges
+	sort.Slice(pieces, func(i, j int) bool {
+		return pieces[i].First < pieces[j].First
+	})
+	return PiecewiseMapping{Pieces: pieces}.merged()
 }
 
-func (m Mapping) MapRanges(srcRanges []Range) (dstRanges []Range, remainderRanges []Range) {
-	for _, src := range srcRanges {
-		d, r := m.MapRange(src)
-		dstRanges = append(dstRanges, d...)
-		remainderRanges = append(remainderRanges, r...)
+// addClamped adds an offset to a value and saturates at the limits of int instead of overflowing,
+// which would otherwise happen for open-ended pieces ending at math.MaxInt with a positive offset.
+func addClamped(val, offset int) int {
+	if offset > 0 && val > math.MaxInt-offset {
+		return math.MaxInt
 	}
-	return
+	if offset < 0 && val < math.MinInt-offset {
+		return math.MinInt
+	}
+	return val + offset
 }
 
-func (m Mapping) MapRange(srcRange Range) (dstRanges []Range, remainderRanges []Range) {
-	if srcRange.First > (m.SrcStart + m.Range - 1) {
-		return nil, []Range{srcRange}
+func (pm PiecewiseMapping) merged() PiecewiseMapping {
+	pieces := make([]Piece, 0, len(pm.Pieces))
+	for _, p := range pm.Pieces {
+		if len(pieces) > 0 && pieces[len(pieces)-1].Last+1 == p.First && pieces[len(pieces)-1].Offset == p.Offset {
+			pieces[len(pieces)-1].Last = p.Last
+		} else {
+			pieces = append(pieces, p)
+		}
 	}
-	if srcRange.Last < m.SrcStart {
-		return nil, []Range{srcRange}
+	return PiecewiseMapping{Pieces: pieces}
+}
+
+// Breakpoints returns the first value of every piece.
+func (pm PiecewiseMapping) Breakpoints() []int {
+	breakpoints := make([]int, len(pm.Pieces))
+	for i, p := range pm.Pieces {
+		breakpoints[i] = p.First
 	}
-	if srcRange.First < m.SrcStart {
-		remainderRanges = append(remainderRanges, Range{First: srcRange.First, Last: m.SrcStart - 1})
-		srcRange.First = m.SrcStart
+	return breakpoints
+}
+
+// Invert returns the mapping from destination values back to all source values mapping to them.
+func (pm PiecewiseMapping) Invert() PiecewiseMapping {
+	pieces := make([]Piece, len(pm.Pieces))
+	for i, p := range pm.Pieces {
+		pieces[i] = Piece{First: addClamped(p.First, p.Offset), Last: addClamped(p.Last, p.Offset), Offset: -p.Offset}
+	}
+	sort.Slice(pieces, func(i, j int) bool {
+		return pieces[i].First < pieces[j].First
+	})
+	return PiecewiseMapping{Pieces: pieces}
+}
+
+func (pm PiecewiseMapping) Map(val int) (int, bool) {
+	for _, p := range pm.Pieces {
+		if val >= p.First && val <= p.Last {
+			return val + p.Offset, true
+		}
+	}
+	return 0, false
+}
+
+func (pm PiecewiseMapping) MapRanges(srcRanges []Range) []Range {
+	dstRanges := make([]Range, 0)
+	for _, r := range srcRanges {
+		for _, p := range pm.Pieces {
+			first := helper.Max(r.First, p.First)
+			last := helper.Min(r.Last, p.Last)
+			if first <= last {
+				dstRanges = append(dstRanges, Range{First: first + p.Offset, Last: last + p.Offset})
+			}
+		}
 	}
-	if srcRange.Last > m.SrcStart+m.Range-1 {
-		remainderRanges = append(remainderRanges, Range{First: m.SrcStart + m.Range, Last: srcRange.Last})
-		srcRange.Last = m.SrcStart + m.Range - 1
+	return dstRanges
+}
+
+// LowestValue returns the lowest mapped value of all ranges by only checking range starts and breakpoints,
+// because values are increasing within every piece.
+func (pm PiecewiseMapping) LowestValue(srcRanges []Range) int {
+	lowest := math.MaxInt
+	for _, r := range srcRanges {
+		for _, p := range pm.Pieces {
+			if r.First <= p.Last && r.Last >= p.First {
+				lowest = helper.Min(lowest, helper.Max(r.First, p.First)+p.Offset)
+			}
+		}
 	}
-	return []Range{{
-		First: srcRange.First - m.SrcStart + m.DstStart,
-		Last:  srcRange.Last - m.SrcStart + m.DstStart,
-	}}, remainderRanges
+	return lowest
 }
 
 func GetSeedRangesPart1(seedRanges []Range) []Range {
@@ -161,13 +264,3 @@ func GetSeedRangesPart1(seedRanges []Range) []Range {
 	}
 	return seedRanges1
 }
-
-func GetLowestRangeValue(ranges []Range) int {
-	min := ranges[0].First
-	for _, r := range ranges {
-		if r.First < min {
-			min = r.First
-		}
-	}
-	return min
-}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"aoc/helper"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSolutions(t *testing.T) {
+	for _, tc := range []struct {
+		file         string
+		part1, part2 int
+	}{
+		{"example-1.txt", 35, 46},
+		{"input.txt", 175622908, 5200543},
+	} {
+		seedRanges2, mapChain := ParseInput(helper.ReadLines(tc.file))
+		seedToLocation := mapChain.Compile()
+		if result := seedToLocation.LowestValue(GetSeedRangesPart1(seedRanges2)); result != tc.part1 {
+			t.Errorf("%s: part 1 is %d instead of %d", tc.file, result, tc.part1)
+		}
+		if result := seedToLocation.LowestValue(seedRanges2); result != tc.part2 {
+			t.Errorf("%s: part 2 is %d instead of %d", tc.file, result, tc.part2)
+		}
+	}
+}
+
+// mapSeed maps a single seed group by group with the first matching mapping of each group.
+func mapSeed(mc MapChain, seed int) int {
+	val := seed
+	for _, mg := range mc.MappingGroups {
+		for _, m := range mg.Mappings {
+			if val >= m.SrcStart && val < m.SrcStart+m.Range {
+				val += m.DstStart - m.SrcStart
+				break
+			}
+		}
+	}
+	return val
+}
+
+// TestCompiledMapping compares the compiled mapping with mapping single seeds through all groups.
+func TestCompiledMapping(t *testing.T) {
+	for _, file := range []string{"example-1.txt", "input.txt"} {
+		seedRanges, mapChain := ParseInput(helper.ReadLines(file))
+		seedToLocation := mapChain.Compile()
+		seeds := []int{0, 1, math.MaxInt}
+		for _, r := range seedRanges {
+			seeds = append(seeds, r.First, (r.First+r.Last)/2, r.Last)
+		}
+		for _, b := range seedToLocation.Breakpoints() {
+			seeds = append(seeds, b)
+			if b > 0 {
+				seeds = append(seeds, b-1)
+			}
+		}
+		for _, seed := range seeds {
+			if got, _ := seedToLocation.Map(seed); got != mapSeed(mapChain, seed) {
+				t.Errorf("%s: seed %d is mapped to %d instead of %d", file, seed, got, mapSeed(mapChain, seed))
+			}
+		}
+	}
+}
+
+func TestInvertRoundTrip(t *testing.T) {
+	identity := PiecewiseMapping{Pieces: []Piece{{First: 0, Last: math.MaxInt, Offset: 0}}}
+	for _, file := range []string{"example-1.txt", "input.txt"} {
+		_, mapChain := ParseInput(helper.ReadLines(file))
+		seedToLocation := mapChain.Compile()
+		inverse := seedToLocation.Invert()
+		// all maps of the puzzle are bijections, so both directions cover all non-negative values
+		if roundTrip := seedToLocation.Then(inverse); !reflect.DeepEqual(roundTrip, identity) {
+			t.Errorf("%s: mapping and its inverse are %v instead of the identity", file, roundTrip.Pieces)
+		}
+		if roundTrip := inverse.Then(seedToLocation); !reflect.DeepEqual(roundTrip, identity) {
+			t.Errorf("%s: inverse and its mapping are %v instead of the identity", file, roundTrip.Pieces)
+		}
+	}
+}
+
+func TestInvertNonInjective(t *testing.T) {
+	// 0..9 and 20..29 both map to 0..9, 10..19 has no preimage
+	group := MappingGroup{Mappings: []Mapping{{SrcStart: 20, DstStart: 0, Range: 10}, {SrcStart: 10, DstStart: 30, Range: 10}}}
+	m := group.Compile()
+	inverse := m.Invert()
+	for _, tc := range []struct {
+		location int
+		seeds    []Range
+	}{
+		{5, []Range{{First: 5, Last: 5}, {First: 25, Last: 25}}},
+		{15, []Range{}},
+		{35, []Range{{First: 15, Last: 15}, {First: 35, Last: 35}}},
+		{math.MaxInt, []Range{{First: math.MaxInt, Last: math.MaxInt}}},
+	} {
+		if seeds := inverse.MapRanges([]Range{{First: tc.location, Last: tc.location}}); !reflect.DeepEqual(seeds, tc.seeds) {
+			t.Errorf("location %d has seeds %v instead of %v", tc.location, seeds, tc.seeds)
+		}
+	}
+	// every value in the image maps back onto itself
+	for val := 0; val < 50; val++ {
+		mapped, _ := m.Map(val)
+		found := false
+		for _, r := range inverse.MapRanges([]Range{{First: mapped, Last: mapped}}) {
+			found = found || r.First == val
+		}
+		if !found {
+			t.Errorf("%d is mapped to %d, but not part of its preimage", val, mapped)
+		}
+	}
+}
+
+func TestThenOverflow(t *testing.T) {
+	// the open-ended piece is shifted upwards and must not wrap around to negative values
+	shift := PiecewiseMapping{Pieces: []Piece{{First: 0, Last: math.MaxInt, Offset: 10}}}
+	identity := PiecewiseMapping{Pieces: []Piece{{First: 0, Last: math.MaxInt, Offset: 0}}}
+	want := []Piece{{First: 0, Last: math.MaxInt - 10, Offset: 10}}
+	if combined := shift.Then(identity); !reflect.DeepEqual(combined.Pieces, want) {
+		t.Errorf("combined mapping is %v instead of %v", combined.Pieces, want)
+	}
+	if roundTrip := shift.Then(shift.Invert()); !reflect.DeepEqual(roundTrip.Pieces, []Piece{{First: 0, Last: math.MaxInt - 10, Offset: 0}}) {
+		t.Errorf("round trip is %v", roundTrip.Pieces)
+	}
+}
@@ -5,7 +5,7 @@ package main
 import (
 	"aoc/helper"
 	"fmt"
-	"strconv"
+	"math/big"
 	"strings"
 )
 
@@ -15,24 +15,19 @@ func main() {
 	races := ParseRaces(lines)
 	solution1 := ComputeSolution(races)
 	race2 := AccountForBadKerning(races)
-	solution2 := ComputeSolution([]Race{race2})
+	solution2 := race2.NumberOfWinningHoldTimes()
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
 }
 
-type Race struct {
-	Time     int
-	Distance int
-}
-
-func ParseRaces(lines []string) []Race {
+func ParseRaces(lines []string) []BigRace {
 	times := ParseInts(lines[0])
 	distances := ParseInts(lines[1])
 	if len(times) != len(distances) {
 		helper.ExitWithMessage("mismatching times and distances count")
 	}
-	races := make([]Race, len(times))
+	races := make([]BigRace, len(times))
 	for i := range times {
 		races[i].Time = times[i]
 		races[i].Distance = distances[i]
@@ -40,57 +35,94 @@ func ParseRaces(lines []string) []Race {
 	return races
 }
 
-func ParseInts(line string) []int {
+func ParseInts(line string) []*big.Int {
 	pos := strings.IndexRune(line, ':')
 	line = line[pos+1:]
 	parts := strings.Split(line, " ")
-	ints := make([]int, 0)
+	ints := make([]*big.Int, 0)
 	for _, p := range parts {
 		if len(p) > 0 {
-			num, err := strconv.Atoi(p)
-			helper.ExitOnError(err, "invalid int value %q", p)
+			num, ok := new(big.Int).SetString(p, 10)
+			if !ok {
+				helper.ExitWithMessage("invalid int value %q", p)
+			}
 			ints = append(ints, num)
 		}
 	}
 	return ints
 }
 
-func (r Race) Simulate(holdTime int) int {
-	if holdTime > r.Time {
-		helper.ExitWithMessage("cannot simulate a holdTime longer than the actual race time")
+func ComputeSolution(races []BigRace) *big.Int {
+	product := big.NewInt(1)
+	for _, r := range races {
+		product.Mul(product, r.NumberOfWinningHoldTimes())
 	}
-	dv := holdTime
-	moveTime := r.Time - holdTime
-	return dv * moveTime
+	return product
 }
 
-func (r Race) NumberOfWinningHoldTimes() int {
-	count := 0
-	for i := 0; i <= r.Time; i++ {
-		if r.Simulate(i) > r.Distance {
-			count++
-		}
+// BigRace supports race times and distances beyond int64.
+type BigRace struct {
+	Time     *big.Int
+	Distance *big.Int
+}
+
+// WinningHoldTimes returns the first and last hold time that beats the distance, ok is false if there is none.
+func (r BigRace) WinningHoldTimes() (*big.Int, *big.Int, bool) {
+	// holdTime*(time-holdTime) > distance  <=>  holdTime^2 - time*holdTime + distance < 0
+	// with roots (time ± sqrt(time^2 - 4*distance)) / 2
+	disc := new(big.Int).Mul(r.Time, r.Time)
+	disc.Sub(disc, new(big.Int).Lsh(r.Distance, 2))
+	if disc.Sign() < 0 {
+		return nil, nil, false
+	}
+	sqrtDisc := new(big.Int).Sqrt(disc)
+
+	// start at the rounded down lower root and fix rounding by checking the neighbours exactly
+	first := new(big.Int).Sub(r.Time, sqrtDisc)
+	first.Rsh(first, 1)
+	if first.Sign() < 0 {
+		// negative distances are already beaten without holding the button
+		first.SetInt64(0)
 	}
-	return count
+	one := big.NewInt(1)
+	for first.Sign() > 0 && r.Wins(new(big.Int).Sub(first, one)) {
+		first.Sub(first, one)
+	}
+	// the rounded root is at most two below the first winning hold time, so there is none if both steps fail
+	for i := 0; i < 2 && first.Cmp(r.Time) <= 0 && !r.Wins(first); i++ {
+		first.Add(first, one)
+	}
+	if first.Cmp(r.Time) > 0 || !r.Wins(first) {
+		return nil, nil, false
+	}
+
+	// the distance is symmetric around time/2
+	last := new(big.Int).Sub(r.Time, first)
+	return first, last, true
 }
 
-func ComputeSolution(races []Race) int {
-	product := 1
-	for _, r := range races {
-		v := r.NumberOfWinningHoldTimes()
-		product *= v
+func (r BigRace) Wins(holdTime *big.Int) bool {
+	moveTime := new(big.Int).Sub(r.Time, holdTime)
+	return new(big.Int).Mul(holdTime, moveTime).Cmp(r.Distance) > 0
+}
+
+func (r BigRace) NumberOfWinningHoldTimes() *big.Int {
+	first, last, ok := r.WinningHoldTimes()
+	if !ok {
+		return big.NewInt(0)
 	}
-	return product
+	count := new(big.Int).Sub(last, first)
+	return count.Add(count, big.NewInt(1))
 }
 
-func AccountForBadKerning(races []Race) Race {
+func AccountForBadKerning(races []BigRace) BigRace {
 	var timeStr, distanceStr string
 	for _, r := range races {
-		timeStr += strconv.Itoa(r.Time)
-		distanceStr += strconv.Itoa(r.Distance)
+		timeStr += r.Time.String()
+		distanceStr += r.Distance.String()
 	}
-	var race Race
-	race.Time, _ = strconv.Atoi(timeStr)
-	race.Distance, _ = strconv.Atoi(distanceStr)
+	race := BigRace{Time: new(big.Int), Distance: new(big.Int)}
+	race.Time.SetString(timeStr, 10)
+	race.Distance.SetString(distanceStr, 10)
 	return race
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"aoc/helper"
+	"math/big"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	races := ParseRaces(helper.ReadLines("example-1.txt"))
+	if solution := ComputeSolution(races); solution.Int64() != 288 {
+		t.Errorf("part 1 is %s instead of 288", solution)
+	}
+	if solution := AccountForBadKerning(races).NumberOfWinningHoldTimes(); solution.Int64() != 71503 {
+		t.Errorf("part 2 is %s instead of 71503", solution)
+	}
+}
+
+func TestBeyondInt64(t *testing.T) {
+	// time 2*10^20 with its maximum distance 10^40
+	races := ParseRaces([]string{
+		"Time:      200000000000000000000 200000000000000000000 200000000000000000000",
+		"Distance:  0 9999999999999999999999999999999999999999 10000000000000000000000000000000000000000",
+	})
+	for i, want := range []string{"199999999999999999999", "1", "0"} {
+		if count := races[i].NumberOfWinningHoldTimes(); count.String() != want {
+			t.Errorf("race %d has %s instead of %s winning hold times", i, count, want)
+		}
+	}
+}
+
+func TestNoWinningHoldTimes(t *testing.T) {
+	for _, r := range []BigRace{
+		{Time: big.NewInt(0), Distance: big.NewInt(0)},
+		{Time: big.NewInt(7), Distance: big.NewInt(12)},
+		{Time: big.NewInt(8), Distance: big.NewInt(16)},
+	} {
+		if _, _, ok := r.WinningHoldTimes(); ok {
+			t.Errorf("race with time %s and distance %s can be won", r.Time, r.Distance)
+		}
+		if count := r.NumberOfWinningHoldTimes(); count.Sign() != 0 {
+			t.Errorf("race with time %s and distance %s has %s winning hold times", r.Time, r.Distance, count)
+		}
+	}
+}
+
+func TestSmallRaces(t *testing.T) {
+	// includes negative distances, which are beaten by every hold time
+	for time := int64(0); time <= 30; time++ {
+		for distance := int64(-5); distance <= time*time/4+1; distance++ {
+			r := BigRace{Time: big.NewInt(time), Distance: big.NewInt(distance)}
+			var want int64
+			for holdTime := int64(0); holdTime <= time; holdTime++ {
+				if holdTime*(time-holdTime) > distance {
+					want++
+				}
+			}
+			if count := r.NumberOfWinningHoldTimes(); count.Int64() != want {
+				t.Errorf("race with time %d and distance %d has %s instead of %d winning hold times", time, distance, count, want)
+			}
+		}
+	}
+}
@@ -8,41 +8,41 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
-)
-
-const (
-	TypeFiveOfAKind  Type = 6
-	TypeFourOfAKind  Type = 5
-	TypeFullHouse    Type = 4
-	TypeThreeOfAKind Type = 3
-	TypeTwoPair      Type = 2
-	TypeOnePair      Type = 1
-	TypeHighCard     Type = 0
+	"strings"
 )
 
 func main() {
 	lines := helper.ReadNonEmptyLines("input.txt")
 
-	bids := ParseBids(lines)
-	solution1 := ComputeSolution(bids, false)
-	solution2 := ComputeSolution(bids, true)
+	rules1 := CamelCardsRuleset()
+	rules2 := CamelCardsWithJokerRuleset()
+	bids := ParseBids(lines, rules1, rules2)
+	solution1 := ComputeSolution(bids, rules1)
+	solution2 := ComputeSolution(bids, rules2)
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
 }
 
-var patternBid = regexp.MustCompile(`^([23456789TJQKA]{5})\s+(\d+)$`)
+var patternBid = regexp.MustCompile(`^(\S+)\s+(\d+)$`)
 
-func ParseBids(lines []string) []Bid {
+// ParseBids exits if a hand is invalid under any of the given rulesets.
+func ParseBids(lines []string, rulesets ...Ruleset) []Bid {
 	bids := make([]Bid, 0, len(lines))
 	for _, line := range lines {
 		m := patternBid.FindStringSubmatch(line)
 		if len(m) != 3 {
 			helper.ExitWithMessage("invalid bid line %q", line)
 		}
+		hand := Hand(m[1])
+		for _, rules := range rulesets {
+			if err := rules.Validate(hand); err != nil {
+				helper.ExitWithMessage("invalid bid line %q: %s", line, err.Error())
+			}
+		}
 		bid, _ := strconv.Atoi(m[2])
 		bids = append(bids, Bid{
-			Hand: [5]Card{Card(m[1][0]), Card(m[1][1]), Card(m[1][2]), Card(m[1][3]), Card(m[1][4])},
+			Hand: hand,
 			Bid:  bid,
 		})
 	}
@@ -54,10 +54,10 @@ type Bid struct {
 	Bid  int
 }
 
-type Hand [5]Card
+type Hand []Card
 
 func (h Hand) String() string {
-	return string(h[0]) + string(h[1]) + string(h[2]) + string(h[3]) + string(h[4])
+	return string(h)
 }
 
 type Card rune
@@ -66,125 +66,238 @@ func (c Card) String() string {
 	return string(c)
 }
 
-func (c Card) Value(withJoker bool) int {
-	if withJoker && c == 'J' {
-		return 0
-	}
-	if c >= '2' && c <= '9' {
-		return int(c-'2') + 2
-	}
-	switch c {
-	case 'T':
-		return 10
-	case 'J':
-		return 11
-	case 'Q':
-		return 12
-	case 'K':
-		return 13
-	case 'A':
-		return 14
-	default:
-		panic(fmt.Sprintf("unknown card %q", c))
+// Ruleset defines how hands are ranked.
+type Ruleset struct {
+	// CardOrder lists all valid cards from weakest to strongest.
+	CardOrder string
+	// Wildcards act like whatever non-wildcard card results in the strongest hand type.
+	Wildcards string
+	HandSize  int
+	// Types lists all hand types from strongest to weakest, the first matching type determines the strength of a hand.
+	Types []HandType
+	// Suit returns the suit of a card, flushes can only occur if it is set.
+	Suit func(c Card) rune
+}
+
+type HandType struct {
+	Name    string
+	Matches func(p HandProfile) bool
+}
+
+func (t HandType) String() string {
+	return t.Name
+}
+
+// HandProfile describes the properties of a hand that are relevant to determine its type.
+type HandProfile struct {
+	// Counts contains the number of equal cards per distinct card in descending order.
+	Counts   []int
+	Straight bool
+	Flush    bool
+}
+
+func (p HandProfile) HasCounts(counts ...int) bool {
+	if len(counts) > len(p.Counts) {
+		return false
 	}
+	for i := range counts {
+		if p.Counts[i] < counts[i] {
+			return false
+		}
+	}
+	return true
 }
 
-type Type int
+var (
+	TypeFiveOfAKind  = HandType{Name: "five-of-a-kind", Matches: func(p HandProfile) bool { return p.HasCounts(5) }}
+	TypeFourOfAKind  = HandType{Name: "four-of-a-kind", Matches: func(p HandProfile) bool { return p.HasCounts(4) }}
+	TypeFullHouse    = HandType{Name: "full-house", Matches: func(p HandProfile) bool { return p.HasCounts(3, 2) }}
+	TypeThreeOfAKind = HandType{Name: "three-of-a-kind", Matches: func(p HandProfile) bool { return p.HasCounts(3) }}
+	TypeTwoPair      = HandType{Name: "two-pair", Matches: func(p HandProfile) bool { return p.HasCounts(2, 2) }}
+	TypeOnePair      = HandType{Name: "one-pair", Matches: func(p HandProfile) bool { return p.HasCounts(2) }}
+	TypeHighCard     = HandType{Name: "high-card", Matches: func(p HandProfile) bool { return true }}
+
+	TypeStraightFlush = HandType{Name: "straight-flush", Matches: func(p HandProfile) bool { return p.Straight && p.Flush }}
+	TypeFlush         = HandType{Name: "flush", Matches: func(p HandProfile) bool { return p.Flush }}
+	TypeStraight      = HandType{Name: "straight", Matches: func(p HandProfile) bool { return p.Straight }}
+)
 
-func (t Type) String() string {
-	switch t {
-	case TypeFiveOfAKind:
-		return "five-of-a-kind"
-	case TypeFourOfAKind:
-		return "four-of-a-kind"
-	case TypeFullHouse:
-		return "full-house"
-	case TypeThreeOfAKind:
-		return "three-of-a-kind"
-	case TypeTwoPair:
-		return "two-pair"
-	case TypeOnePair:
-		return "one-pair"
-	default:
-		return "high-card"
+func CamelCardsRuleset() Ruleset {
+	return Ruleset{
+		CardOrder: "23456789TJQKA",
+		HandSize:  5,
+		Types:     []HandType{TypeFiveOfAKind, TypeFourOfAKind, TypeFullHouse, TypeThreeOfAKind, TypeTwoPair, TypeOnePair, TypeHighCard},
 	}
 }
 
-func (h Hand) GetType(withJoker bool) Type {
-	cardCounts := make(map[Card]int)
+func CamelCardsWithJokerRuleset() Ruleset {
+	return Ruleset{
+		CardOrder: "J23456789TQKA",
+		Wildcards: "J",
+		HandSize:  5,
+		Types:     []HandType{TypeFiveOfAKind, TypeFourOfAKind, TypeFullHouse, TypeThreeOfAKind, TypeTwoPair, TypeOnePair, TypeHighCard},
+	}
+}
+
+// PokerRuleset ranks suitless poker hands: flushes only occur if a suit function is set afterwards.
+func PokerRuleset() Ruleset {
+	return Ruleset{
+		CardOrder: "23456789TJQKA",
+		HandSize:  5,
+		Types:     []HandType{TypeFiveOfAKind, TypeStraightFlush, TypeFourOfAKind, TypeFullHouse, TypeFlush, TypeStraight, TypeThreeOfAKind, TypeTwoPair, TypeOnePair, TypeHighCard},
+	}
+}
+
+func (r Ruleset) Validate(h Hand) error {
+	if len(h) != r.HandSize {
+		return fmt.Errorf("hand %q has %d cards instead of %d", h, len(h), r.HandSize)
+	}
 	for _, c := range h {
-		currentCount := cardCounts[c]
-		cardCounts[c] = currentCount + 1
+		if !strings.ContainsRune(r.CardOrder, rune(c)) {
+			return fmt.Errorf("unknown card %q in hand %q", c, h)
+		}
 	}
+	return nil
+}
 
-	var jokerCount int
-	if withJoker {
-		var ok bool
-		jokerCount, ok = cardCounts['J']
-		if ok {
-			delete(cardCounts, 'J')
+func (r Ruleset) CardValue(c Card) int {
+	value := strings.IndexRune(r.CardOrder, rune(c))
+	if value < 0 {
+		panic(fmt.Sprintf("unknown card %q", c))
+	}
+	return value
+}
+
+func (r Ruleset) IsWildcard(c Card) bool {
+	return strings.ContainsRune(r.Wildcards, rune(c))
+}
+
+// GetType returns the strongest hand type for a hand and its strength, where stronger types have higher values.
+func (r Ruleset) GetType(h Hand) (HandType, int) {
+	fixedCards := make(Hand, 0, len(h))
+	var wildcardCount int
+	for _, c := range h {
+		if r.IsWildcard(c) {
+			wildcardCount++
+		} else {
+			fixedCards = append(fixedCards, c)
 		}
+	}
+	if wildcardCount == 0 {
+		return r.getTypeOfFixedHand(fixedCards)
+	}
 
-		if jokerCount == 5 {
-			return TypeFiveOfAKind
+	substitutes := make(Hand, 0, len(r.CardOrder))
+	for _, c := range r.CardOrder {
+		if !r.IsWildcard(Card(c)) {
+			substitutes = append(substitutes, Card(c))
 		}
 	}
 
-	takeNum := func(searchNum int) bool {
-		for c, num := range cardCounts {
-			if num == (searchNum - jokerCount) {
-				delete(cardCounts, c)
-				jokerCount = 0
-				return true
-			}
+	// the order of substituted cards is irrelevant for the type, so only combinations with repetition are tested
+	bestIndex := len(r.Types)
+	candidate := make(Hand, len(h))
+	copy(candidate, fixedCards)
+	var substitute func(pos, minSubstitute int)
+	substitute = func(pos, minSubstitute int) {
+		if pos == len(candidate) {
+			_, index := r.getTypeIndex(candidate)
+			bestIndex = helper.Min(bestIndex, index)
+			return
+		}
+		for i := minSubstitute; i < len(substitutes) && bestIndex > 0; i++ {
+			candidate[pos] = substitutes[i]
+			substitute(pos+1, i)
+		}
+	}
+	substitute(len(fixedCards), 0)
+	if bestIndex >= len(r.Types) {
+		return HandType{Name: "none"}, -1
+	}
+	return r.Types[bestIndex], len(r.Types) - bestIndex - 1
+}
+
+func (r Ruleset) getTypeOfFixedHand(h Hand) (HandType, int) {
+	t, index := r.getTypeIndex(h)
+	return t, len(r.Types) - index - 1
+}
+
+func (r Ruleset) getTypeIndex(h Hand) (HandType, int) {
+	p := r.GetProfile(h)
+	for i, t := range r.Types {
+		if t.Matches(p) {
+			return t, i
 		}
-		return false
 	}
+	return HandType{Name: "none"}, len(r.Types)
+}
 
-	if takeNum(5) {
-		return TypeFiveOfAKind
+// GetProfile returns the profile of a hand without resolving wildcards.
+func (r Ruleset) GetProfile(h Hand) HandProfile {
+	cardCounts := make(map[Card]int)
+	for _, c := range h {
+		cardCounts[c]++
 	}
-	if takeNum(4) {
-		return TypeFourOfAKind
+	counts := make([]int, 0, len(cardCounts))
+	for _, num := range cardCounts {
+		counts = append(counts, num)
 	}
-	if takeNum(3) {
-		if takeNum(2) {
-			return TypeFullHouse
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	straight := len(counts) == len(h) && len(h) > 1
+	if straight {
+		values := make([]int, len(h))
+		for i, c := range h {
+			values[i] = r.CardValue(c)
 		}
-		return TypeThreeOfAKind
+		sort.Ints(values)
+		straight = values[len(values)-1]-values[0] == len(values)-1
 	}
-	if takeNum(2) {
-		if takeNum(2) {
-			return TypeTwoPair
+
+	flush := r.Suit != nil && len(h) > 0
+	if flush {
+		suit := r.Suit(h[0])
+		for _, c := range h[1:] {
+			if r.Suit(c) != suit {
+				flush = false
+				break
+			}
 		}
-		return TypeOnePair
 	}
-	return TypeHighCard
+	return HandProfile{Counts: counts, Straight: straight, Flush: flush}
 }
 
-func LessHand(h1, h2 Hand, withJoker bool) bool {
-	t1 := h1.GetType(withJoker)
-	t2 := h2.GetType(withJoker)
+func LessHand(h1, h2 Hand, rules Ruleset) bool {
+	_, t1 := rules.GetType(h1)
+	_, t2 := rules.GetType(h2)
+	return lessHandWithTypes(h1, h2, t1, t2, rules)
+}
+
+func lessHandWithTypes(h1, h2 Hand, t1, t2 int, rules Ruleset) bool {
 	if t1 < t2 {
 		return true
 	}
 	if t1 > t2 {
 		return false
 	}
-	for i := 0; i < 5; i++ {
-		if h1[i].Value(withJoker) < h2[i].Value(withJoker) {
+	for i := 0; i < len(h1) && i < len(h2); i++ {
+		if rules.CardValue(h1[i]) < rules.CardValue(h2[i]) {
 			return true
 		}
-		if h1[i].Value(withJoker) > h2[i].Value(withJoker) {
+		if rules.CardValue(h1[i]) > rules.CardValue(h2[i]) {
 			return false
 		}
 	}
 	return false
 }
 
-func ComputeSolution(bids []Bid, withJoker bool) int {
+func ComputeSolution(bids []Bid, rules Ruleset) int {
+	types := make(map[string]int, len(bids))
+	for _, b := range bids {
+		_, types[b.Hand.String()] = rules.GetType(b.Hand)
+	}
 	sort.Slice(bids, func(i, j int) bool {
-		return !LessHand(bids[i].Hand, bids[j].Hand, withJoker)
+		return !lessHandWithTypes(bids[i].Hand, bids[j].Hand, types[bids[i].Hand.String()], types[bids[j].Hand.String()], rules)
 	})
 	totalWinnings := 0
 	for i := range bids {
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"aoc/helper"
+	"testing"
+)
+
+func TestExample(t *testing.T) {
+	lines := helper.ReadNonEmptyLines("example-1.txt")
+	rules1, rules2 := CamelCardsRuleset(), CamelCardsWithJokerRuleset()
+	bids := ParseBids(lines, rules1, rules2)
+	if result := ComputeSolution(bids, rules1); result != 6440 {
+		t.Errorf("part 1 is %d instead of 6440", result)
+	}
+	if result := ComputeSolution(bids, rules2); result != 5905 {
+		t.Errorf("part 2 is %d instead of 5905", result)
+	}
+}
+
+func TestPokerRuleset(t *testing.T) {
+	rules := PokerRuleset()
+	bids := ParseBids(helper.ReadNonEmptyLines("example-1.txt"), rules)
+	// the example hands contain no straights, so poker ranks them like camel cards
+	if result := ComputeSolution(bids, rules); result != 6440 {
+		t.Errorf("example is %d instead of 6440", result)
+	}
+
+	suited := PokerRuleset()
+	suited.Suit = func(c Card) rune {
+		if rules.CardValue(c)%2 == 0 {
+			return 'h'
+		}
+		return 's'
+	}
+	monochrome := PokerRuleset()
+	monochrome.Suit = func(c Card) rune { return 'h' }
+	for _, tc := range []struct {
+		rules Ruleset
+		hand  string
+		want  HandType
+	}{
+		{rules, "23456", TypeStraight},
+		{rules, "AKQJT", TypeStraight},
+		{rules, "2345A", TypeHighCard},
+		{rules, "22333", TypeFullHouse},
+		{suited, "2468T", TypeFlush},
+		{suited, "23456", TypeStraight},
+		{suited, "22357", TypeOnePair},
+		{monochrome, "23456", TypeStraightFlush},
+		{monochrome, "22333", TypeFullHouse},
+		{monochrome, "2345A", TypeFlush},
+	} {
+		if got, _ := tc.rules.GetType(Hand(tc.hand)); got.Name != tc.want.Name {
+			t.Errorf("type of %s is %s instead of %s", tc.hand, got, tc.want)
+		}
+	}
+}
+
+// TestWildcardSearch compares the combination search of GetType with substituting every wildcard by every card independently.
+func TestWildcardSearch(t *testing.T) {
+	jokerPoker := PokerRuleset()
+	jokerPoker.Wildcards = "J"
+	for _, tc := range []struct {
+		name  string
+		rules Ruleset
+		cards string
+	}{
+		// distinct non-wildcard cards for all shapes of fixed cards
+		{"camel", CamelCardsWithJokerRuleset(), "J23456"},
+		// consecutive cards to allow straights
+		{"poker", jokerPoker, "J89TQK2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hand := make(Hand, tc.rules.HandSize)
+			var enumerate func(pos int)
+			enumerate = func(pos int) {
+				if pos == len(hand) {
+					_, got := tc.rules.GetType(hand)
+					if want := exhaustiveTypeStrength(tc.rules, hand); got != want {
+						t.Fatalf("strength of %s is %d instead of %d", hand, got, want)
+					}
+					return
+				}
+				for _, c := range tc.cards {
+					hand[pos] = Card(c)
+					enumerate(pos + 1)
+				}
+			}
+			enumerate(0)
+		})
+	}
+}
+
+func exhaustiveTypeStrength(rules Ruleset, h Hand) int {
+	candidate := make(Hand, len(h))
+	copy(candidate, h)
+	best := -1
+	var substitute func(pos int)
+	substitute = func(pos int) {
+		if pos == len(candidate) {
+			_, index := rules.getTypeIndex(candidate)
+			best = helper.Max(best, len(rules.Types)-index-1)
+			return
+		}
+		if !rules.IsWildcard(h[pos]) {
+			substitute(pos + 1)
+			return
+		}
+		for _, c := range rules.CardOrder {
+			if !rules.IsWildcard(Card(c)) {
+				candidate[pos] = Card(c)
+				substitute(pos + 1)
+			}
+		}
+	}
+	substitute(0)
+	return best
+}
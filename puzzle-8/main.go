@@ -4,7 +4,11 @@ package main
 
 import (
 	"aoc/helper"
+	"aoc/helper/dot"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strings"
@@ -16,16 +20,22 @@ const (
 )
 
 func main() {
+	dotFile := flag.String("dot", "", "write network as DOT graph to file")
+	flag.Parse()
+
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	sequence, nodes := ParseInput(lines)
-	mover := NetworkMover{
-		Sequence:    sequence,
-		Network:     nodes,
-		DirectLinks: make(map[DirectLinkHeader]string),
+	if len(*dotFile) > 0 {
+		helper.ExitOnError(nodes.ToDot().WriteFile(*dotFile), "write dot file")
+	}
+	mover := NewNetworkMover(sequence, nodes)
+	solution1 := GetPathLength(mover, "AAA", "ZZZ")
+	solution2, err := GetGhostPathLength(mover)
+	helper.ExitOnError(err, "compute ghost path length")
+	if !IsEndPosition(mover.MoveMany(GetStartPositions(nodes), 0, solution2)) {
+		helper.ExitWithMessage("not all ghosts are on end nodes after %d steps", solution2)
 	}
-	solution1 := GetPathLength(&mover, "AAA", "ZZZ")
-	solution2 := GetGhostPathLength(&mover)
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
@@ -33,6 +43,24 @@ func main() {
 
 type Network map[string]Node
 
+func (n Network) ToDot() *dot.Graph {
+	g := dot.NewGraph("network", true)
+	helper.IterateMapInKeyOrder(n, func(name string, node Node) {
+		var attrs dot.Attributes
+		if strings.HasSuffix(name, "A") {
+			attrs = dot.Attributes{"shape": "house", "style": "filled", "fillcolor": "palegreen"}
+		} else if strings.HasSuffix(name, "Z") {
+			attrs = dot.Attributes{"shape": "doublecircle", "style": "filled", "fillcolor": "salmon"}
+		}
+		g.AddNode(name, attrs)
+	})
+	helper.IterateMapInKeyOrder(n, func(name string, node Node) {
+		g.AddEdge(name, node.Left, dot.Attributes{"label": DirLeft.String(), "color": "blue"})
+		g.AddEdge(name, node.Right, dot.Attributes{"label": DirRight.String(), "color": "red"})
+	})
+	return g
+}
+
 type Node struct {
 	Left  string
 	Right string
@@ -83,48 +111,191 @@ func GetPathLength(mover *NetworkMover, from, to string) int64 {
 	return count
 }
 
+// NetworkMover moves over the states (node, sequenceIndex) using jump tables,
+// where Jumps[k][state] is the state reached after 2^k steps.
 type NetworkMover struct {
-	Sequence    []Dir
-	Network     Network
-	DirectLinks map[DirectLinkHeader]string
+	Sequence  []Dir
+	Network   Network
+	Nodes     []string
+	NodeIndex map[string]int
+	Jumps     [][]int32
 }
 
-type DirectLinkHeader struct {
-	Node          string
-	SequenceIndex int
-	Steps         int64
+func NewNetworkMover(sequence []Dir, network Network) *NetworkMover {
+	nodes := make([]string, 0, len(network))
+	helper.IterateMapInKeyOrder(network, func(name string, _ Node) {
+		nodes = append(nodes, name)
+	})
+	nodeIndex := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		nodeIndex[n] = i
+	}
+
+	stateCount := len(nodes) * len(sequence)
+	if stateCount > math.MaxInt32 {
+		helper.ExitWithMessage("too many states (%d) for jump table", stateCount)
+	}
+	firstJump := make([]int32, stateCount)
+	for i, n := range nodes {
+		for j, d := range sequence {
+			next, ok := nodeIndex[network[n].GetNext(d)]
+			if !ok {
+				helper.ExitWithMessage("node %s links to unknown node %s", n, network[n].GetNext(d))
+			}
+			firstJump[i*len(sequence)+j] = int32(next*len(sequence) + (j+1)%len(sequence))
+		}
+	}
+	return &NetworkMover{
+		Sequence:  sequence,
+		Network:   network,
+		Nodes:     nodes,
+		NodeIndex: nodeIndex,
+		Jumps:     [][]int32{firstJump},
+	}
 }
 
-func GetGhostPathLength(mover *NetworkMover) int64 {
-	// least common multiple 19637 = 19637 = empirische Lösung
-	// least common multiple 19637,12643 = 922939 = empirische Lösung
-	// least common multiple 19637,12643,11567 = 39686377 = empirische Lösung
-	// least common multiple 19637,12643,11567,15871,14257,19099 = 8811050362409 (= Vermutung: Entspricht auch der empirischen Lösung -> Tatsächlich! :D)
-	// MANY assumptions here!
+func GetGhostPathLength(mover *NetworkMover) (int64, error) {
 	startPositions := GetStartPositions(mover.Network)
-	solutions := make([]int64, len(startPositions))
+	cycles := make([]GhostCycle, len(startPositions))
 	for i := range startPositions {
-		solutions[i] = GetGhostPathLengthForStartPositions(startPositions[i:i+1], mover)
+		cycles[i] = AnalyzeGhostCycle(mover, startPositions[i])
+	}
+	return CombineGhostCycles(cycles)
+}
+
+// GhostCycle describes the walk of a single ghost over the states (node, sequenceIndex).
+// After Tail steps the ghost enters a cycle of CycleLength steps that repeats forever.
+type GhostCycle struct {
+	Start       string
+	Tail        int64
+	CycleLength int64
+	// TailHits contains all steps before Tail at which the ghost is on an end node.
+	TailHits []int64
+	// CycleHits contains all steps in [Tail, Tail+CycleLength) at which the ghost is on an end node,
+	// every hit repeats after CycleLength steps.
+	CycleHits []int64
+}
+
+func AnalyzeGhostCycle(mover *NetworkMover, start string) GhostCycle {
+	type State struct {
+		Node          string
+		SequenceIndex int
+	}
+	firstSeen := make(map[State]int64)
+	hits := make([]int64, 0)
+	pos := start
+	for step := int64(0); ; step++ {
+		state := State{Node: pos, SequenceIndex: int(step % int64(len(mover.Sequence)))}
+		if tail, ok := firstSeen[state]; ok {
+			c := GhostCycle{Start: start, Tail: tail, CycleLength: step - tail, TailHits: []int64{}, CycleHits: []int64{}}
+			for _, h := range hits {
+				if h < tail {
+					c.TailHits = append(c.TailHits, h)
+				} else {
+					c.CycleHits = append(c.CycleHits, h)
+				}
+			}
+			return c
+		}
+		firstSeen[state] = step
+		if IsEndPosition([]string{pos}) {
+			hits = append(hits, step)
+		}
+		pos = mover.Move(pos, step, 1)
+	}
+}
+
+// IsHit returns true if the ghost is on an end node after the given number of steps.
+func (c GhostCycle) IsHit(step int64) bool {
+	if step < c.Tail {
+		for _, h := range c.TailHits {
+			if h == step {
+				return true
+			}
+		}
+		return false
+	}
+	offset := c.Tail + (step-c.Tail)%c.CycleLength
+	for _, h := range c.CycleHits {
+		if h == offset {
+			return true
+		}
 	}
-	return LeastCommonMultiple(solutions...)
+	return false
 }
 
-func LeastCommonMultiple(vals ...int64) int64 {
-	gcd := func(a, b int64) int64 {
-		for b != 0 {
-			t := b
-			b = a % b
-			a = t
+// CombineGhostCycles returns the first step at which all ghosts are on an end node at the same time.
+func CombineGhostCycles(cycles []GhostCycle) (int64, error) {
+	var maxTail int64
+	for _, c := range cycles {
+		if len(c.TailHits) == 0 && len(c.CycleHits) == 0 {
+			return 0, fmt.Errorf("ghost starting at %s never reaches an end node", c.Start)
 		}
-		return a
+		maxTail = helper.Max(maxTail, c.Tail)
 	}
 
-	result := vals[0] * vals[1] / gcd(vals[0], vals[1])
-	for i := 2; i < len(vals); i++ {
-		result = LeastCommonMultiple(result, vals[i])
+	// before all ghosts are inside their cycles, steps are checked one by one
+	for step := int64(0); step < maxTail; step++ {
+		if allGhostsHit(cycles, step) {
+			return step, nil
+		}
 	}
-	return result
+
+	// afterwards, every ghost adds the condition step ≡ hit (mod cycleLength) for one of its cycle hits.
+	// All candidates share the same modulus after each ghost, so equal residues are merged. The number of candidates
+	// is still bounded by the product of cycle hits per ghost, which is fine for the few end nodes per cycle of the puzzle.
+	type Congruence struct {
+		Residue, Modulus int64
+	}
+	candidates := []Congruence{{Residue: 0, Modulus: 1}}
+	for _, c := range cycles {
+		if len(c.CycleHits) == 0 {
+			return 0, fmt.Errorf("ghost starting at %s only reaches end nodes before entering its cycle", c.Start)
+		}
+		nextCandidates := make([]Congruence, 0)
+		known := make(map[Congruence]bool)
+		for _, cand := range candidates {
+			for _, h := range c.CycleHits {
+				x, m, err := helper.SolveCongruences([]int64{cand.Residue, h}, []int64{cand.Modulus, c.CycleLength})
+				if errors.Is(err, helper.ErrCongruenceOverflow) {
+					// the dropped candidate might contain the first common step
+					return 0, fmt.Errorf("cycles of ghosts exceed int64 after adding ghost starting at %s", c.Start)
+				}
+				if next := (Congruence{Residue: x, Modulus: m}); err == nil && !known[next] {
+					known[next] = true
+					nextCandidates = append(nextCandidates, next)
+				}
+			}
+		}
+		if len(nextCandidates) == 0 {
+			return 0, fmt.Errorf("cycles of ghosts never align after adding ghost starting at %s", c.Start)
+		}
+		candidates = nextCandidates
+	}
+
+	best := int64(-1)
+	for _, cand := range candidates {
+		step := cand.Residue
+		if step < maxTail {
+			step += ((maxTail - step + cand.Modulus - 1) / cand.Modulus) * cand.Modulus
+		}
+		if best < 0 || step < best {
+			best = step
+		}
+	}
+	return best, nil
+}
+
+func allGhostsHit(cycles []GhostCycle, step int64) bool {
+	for _, c := range cycles {
+		if !c.IsHit(step) {
+			return false
+		}
+	}
+	return true
 }
+
+// GetGhostPathLengthForStartPositions moves all ghosts step by step until they are on end nodes at the same time.
 func GetGhostPathLengthForStartPositions(startPositions []string, mover *NetworkMover) int64 {
 	currentPositions := startPositions
 	var count int64
@@ -157,27 +328,37 @@ func (nm *NetworkMover) MoveMany(positions []string, sequenceIndex, steps int64)
 }
 
 func (nm *NetworkMover) Move(pos string, sequenceIndex, steps int64) string {
-	sequenceIndex = sequenceIndex % int64(len(nm.Sequence))
-	hdr := DirectLinkHeader{
-		Node:          pos,
-		SequenceIndex: int(sequenceIndex),
-		Steps:         steps,
+	nodeIndex, ok := nm.NodeIndex[pos]
+	if !ok {
+		helper.ExitWithMessage("unknown node %s", pos)
 	}
+	state := nodeIndex*len(nm.Sequence) + int(sequenceIndex%int64(len(nm.Sequence)))
+	state = nm.MoveState(state, steps)
+	return nm.Nodes[state/len(nm.Sequence)]
+}
 
-	if newPos, ok := nm.DirectLinks[hdr]; ok {
-		return newPos
+// MoveState returns the state after the given number of steps in O(log steps).
+func (nm *NetworkMover) MoveState(state int, steps int64) int {
+	for k := 0; steps > 0; k++ {
+		if steps&1 == 1 {
+			state = int(nm.jumpTable(k)[state])
+		}
+		steps >>= 1
 	}
-	newPos := nm.newMove(pos, sequenceIndex, steps)
-	nm.DirectLinks[hdr] = newPos
-	return newPos
+	return state
 }
 
-func (nm *NetworkMover) newMove(pos string, sequenceIndex, steps int64) string {
-	if steps == 1 {
-		return nm.Network[pos].GetNext(nm.Sequence[sequenceIndex])
+// jumpTable returns the table for 2^k steps and computes all missing tables up to k.
+func (nm *NetworkMover) jumpTable(k int) []int32 {
+	for len(nm.Jumps) <= k {
+		prev := nm.Jumps[len(nm.Jumps)-1]
+		next := make([]int32, len(prev))
+		for i := range prev {
+			next[i] = prev[prev[i]]
+		}
+		nm.Jumps = append(nm.Jumps, next)
 	}
-	// could be optimized, nvm...
-	return nm.Move(nm.Move(pos, sequenceIndex, 1), sequenceIndex+1, steps-1)
+	return nm.Jumps[k]
 }
 
 func IsEndPosition(positions []string) bool {
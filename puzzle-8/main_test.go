@@ -0,0 +1,154 @@
+package main
+
+import (
+	"aoc/helper"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newMover creates a network where every node has the same left and right successor.
+func newMover(links ...string) *NetworkMover {
+	lines := []string{"L"}
+	for _, link := range links {
+		parts := strings.Split(link, "->")
+		from, to := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		lines = append(lines, from+" = ("+to+", "+to+")")
+	}
+	sequence, nodes := ParseInput(lines)
+	return NewNetworkMover(sequence, nodes)
+}
+
+func TestAnalyzeGhostCycle(t *testing.T) {
+	mover := newMover(
+		// the end node is only reached before the cycle B -> C
+		"11A -> 11Z", "11Z -> 11B", "11B -> 11C", "11C -> 11B",
+		// two end nodes in a cycle of length 5 after a tail of 1
+		"22A -> 22B", "22B -> 21Z", "21Z -> 22C", "22C -> 22Z", "22Z -> 22D", "22D -> 22B",
+	)
+	for _, want := range []GhostCycle{
+		{Start: "11A", Tail: 2, CycleLength: 2, TailHits: []int64{1}, CycleHits: []int64{}},
+		{Start: "22A", Tail: 1, CycleLength: 5, TailHits: []int64{}, CycleHits: []int64{2, 4}},
+	} {
+		if c := AnalyzeGhostCycle(mover, want.Start); !reflect.DeepEqual(c, want) {
+			t.Errorf("ghost %s has cycle %+v instead of %+v", want.Start, c, want)
+		}
+	}
+}
+
+func TestCombineGhostCycles(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		links []string
+		want  int64
+	}{
+		{"tail hit", []string{
+			"11A -> 11Z", "11Z -> 11B", "11B -> 11C", "11C -> 11B",
+			"22A -> 22Z", "22Z -> 22A",
+		}, 1},
+		{"several cycle hits with offsets", []string{
+			// hits at 2 and 4 modulo 5
+			"11A -> 11B", "11B -> 12Z", "12Z -> 11C", "11C -> 11Z", "11Z -> 11D", "11D -> 11B",
+			// hits at 1 modulo 3
+			"22A -> 22Z", "22Z -> 22B", "22B -> 22A",
+		}, 4},
+		{"beyond the first cycle", []string{
+			// hits at 2 modulo 5
+			"11A -> 11B", "11B -> 11Z", "11Z -> 11C", "11C -> 11D", "11D -> 11E", "11E -> 11B",
+			// hits at 1 modulo 3
+			"22A -> 22Z", "22Z -> 22B", "22B -> 22A",
+		}, 7},
+		{"different tails", []string{
+			// hits at 3 and 5 modulo 6
+			"11A -> 11B", "11B -> 11C", "11C -> 11Z", "11Z -> 11D", "11D -> 12Z", "12Z -> 11E", "11E -> 11F", "11F -> 11C",
+			// hits at 5 modulo 4 after a tail of 2
+			"22A -> 22B", "22B -> 22C", "22C -> 22D", "22D -> 22E", "22E -> 22Z", "22Z -> 22C",
+		}, 5},
+	} {
+		mover := newMover(tc.links...)
+		got, err := GetGhostPathLength(mover)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err.Error())
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: ghosts meet after %d instead of %d steps", tc.name, got, tc.want)
+		}
+		if bruteForce := GetGhostPathLengthForStartPositions(GetStartPositions(mover.Network), mover); got != bruteForce {
+			t.Errorf("%s: ghosts meet after %d steps, but %d when moving step by step", tc.name, got, bruteForce)
+		}
+	}
+}
+
+func TestCombineGhostCyclesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		links []string
+		err   string
+	}{
+		{"never aligned", []string{
+			// odd steps only
+			"11A -> 11Z", "11Z -> 11A",
+			// even steps only
+			"22A -> 22B", "22B -> 22Z", "22Z -> 22B",
+		}, "never align"},
+		{"tail only", []string{
+			"11A -> 11Z", "11Z -> 11B", "11B -> 11B",
+			"22A -> 22B", "22B -> 22Z", "22Z -> 22B",
+		}, "only reaches end nodes before entering its cycle"},
+		{"no end node", []string{
+			"11A -> 11B", "11B -> 11A",
+			"22A -> 22Z", "22Z -> 22A",
+		}, "never reaches an end node"},
+	} {
+		_, err := GetGhostPathLength(newMover(tc.links...))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error is %v instead of containing %q", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestJumpTables(t *testing.T) {
+	sequence, nodes := ParseInput(helper.ReadNonEmptyLines("input.txt"))
+	mover := NewNetworkMover(sequence, nodes)
+	rnd := rand.New(rand.NewSource(8))
+	for i := 0; i < 20; i++ {
+		start := mover.Nodes[rnd.Intn(len(mover.Nodes))]
+		sequenceIndex := int64(rnd.Intn(len(sequence)))
+		steps := int64(rnd.Intn(50000))
+		pos := start
+		for step := int64(0); step < steps; step++ {
+			pos = mover.Move(pos, sequenceIndex+step, 1)
+		}
+		if jumped := mover.Move(start, sequenceIndex, steps); jumped != pos {
+			t.Errorf("%d steps from %s at sequence index %d end on %s instead of %s", steps, start, sequenceIndex, jumped, pos)
+		}
+	}
+}
+
+func TestGhostPathLengthExamples(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want int64
+	}{
+		{"example-1.txt", 2},
+		{"example-2.txt", 6},
+		{"example-3.txt", 6},
+	} {
+		sequence, nodes := ParseInput(helper.ReadNonEmptyLines(tc.file))
+		mover := NewNetworkMover(sequence, nodes)
+		got, err := GetGhostPathLength(mover)
+		if err != nil {
+			t.Errorf("%s: %s", tc.file, err.Error())
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: ghosts meet after %d instead of %d steps", tc.file, got, tc.want)
+		}
+		// the step by step walk stops at the first common step
+		if bruteForce := GetGhostPathLengthForStartPositions(GetStartPositions(nodes), mover); got != bruteForce {
+			t.Errorf("%s: ghosts meet after %d steps, but first after %d when moving step by step", tc.file, got, bruteForce)
+		}
+	}
+}
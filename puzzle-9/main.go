@@ -5,14 +5,21 @@ package main
 import (
 	"aoc/helper"
 	"fmt"
+	"math/big"
 )
 
 func main() {
 	lines := helper.ReadNonEmptyLines("input.txt")
 
 	sequences := ReadSequences(lines)
-	solution1 := SumExtrapolations(sequences)
-	solution2 := SumReverseExtrapolations(sequences)
+	polynomials := make([]Polynomial, len(sequences))
+	for i, s := range sequences {
+		p, err := s.Polynomial()
+		helper.ExitOnError(err, "sequence %d", i+1)
+		polynomials[i] = p
+	}
+	solution1 := SumValues(polynomials, func(p Polynomial) *big.Rat { return p.Forward(1) })
+	solution2 := SumValues(polynomials, func(p Polynomial) *big.Rat { return p.Backward(1) })
 
 	fmt.Println("-> part 1:", solution1)
 	fmt.Println("-> part 2:", solution2)
@@ -29,53 +36,86 @@ func ReadSequences(lines []string) []Sequence {
 	return sequences
 }
 
-func (s Sequence) Reverse() Sequence {
-	rs := make([]int, len(s))
-	for i := range s {
-		rs[len(s)-i-1] = s[i]
-	}
-	return rs
+// Polynomial models a sequence using Newton's forward difference formula: s(n) = sum_k binomial(n, k) * Δ^k s(0).
+type Polynomial struct {
+	// Differences contains the leading forward differences Δ^k s(0) for k = 0..Degree.
+	Differences []*big.Rat
+	// Length is the number of values of the original sequence.
+	Length int
 }
 
-func (s Sequence) Extrapolate() int {
-	if s.IsAllZeroes() {
-		return 0
+// Polynomial returns the polynomial of lowest degree that generates the sequence.
+// The highest-order differences must be confirmed by at least two equal values, otherwise any n values would fit a polynomial of degree n-1.
+// A single value is the only exception and treated as constant.
+func (s Sequence) Polynomial() (Polynomial, error) {
+	if len(s) == 0 {
+		return Polynomial{}, fmt.Errorf("empty sequence")
 	}
-
-	diff := s.GetDiffSequence()
-	extDiff := diff.Extrapolate()
-	return s[len(s)-1] + extDiff
-}
-
-func (s Sequence) GetDiffSequence() Sequence {
-	diff := make([]int, len(s)-1)
-	for i := range diff {
-		diff[i] = s[i+1] - s[i]
+	row := make([]*big.Rat, len(s))
+	for i := range s {
+		row[i] = new(big.Rat).SetInt64(int64(s[i]))
+	}
+	differences := make([]*big.Rat, 0)
+	for {
+		differences = append(differences, row[0])
+		if len(row) == 1 && len(s) > 1 {
+			return Polynomial{}, fmt.Errorf("sequence of length %d is not polynomial with a degree below %d", len(s), len(s)-1)
+		}
+		if isConstant(row) {
+			return Polynomial{Differences: differences, Length: len(s)}, nil
+		}
+		next := make([]*big.Rat, len(row)-1)
+		for i := range next {
+			next[i] = new(big.Rat).Sub(row[i+1], row[i])
+		}
+		row = next
 	}
-	return diff
 }
 
-func (s Sequence) IsAllZeroes() bool {
-	for _, num := range s {
-		if num != 0 {
+func isConstant(values []*big.Rat) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i].Cmp(values[0]) != 0 {
 			return false
 		}
 	}
 	return true
 }
 
-func SumExtrapolations(sequences []Sequence) int {
-	sum := 0
-	for _, s := range sequences {
-		sum += s.Extrapolate()
+func (p Polynomial) Degree() int {
+	return len(p.Differences) - 1
+}
+
+// At evaluates the polynomial at an arbitrary, possibly negative, index.
+func (p Polynomial) At(index *big.Int) *big.Rat {
+	n := new(big.Rat).SetInt(index)
+	result := new(big.Rat)
+	binomial := big.NewRat(1, 1)
+	for k, d := range p.Differences {
+		if k > 0 {
+			// binomial(n, k) = binomial(n, k-1) * (n-k+1) / k
+			factor := new(big.Rat).Sub(n, big.NewRat(int64(k-1), 1))
+			binomial.Mul(binomial, factor)
+			binomial.Quo(binomial, big.NewRat(int64(k), 1))
+		}
+		result.Add(result, new(big.Rat).Mul(binomial, d))
 	}
-	return sum
+	return result
+}
+
+// Forward returns the value k steps after the last value of the sequence.
+func (p Polynomial) Forward(k int64) *big.Rat {
+	return p.At(big.NewInt(int64(p.Length) - 1 + k))
+}
+
+// Backward returns the value k steps before the first value of the sequence.
+func (p Polynomial) Backward(k int64) *big.Rat {
+	return p.At(big.NewInt(-k))
 }
 
-func SumReverseExtrapolations(sequences []Sequence) int {
-	sum := 0
-	for _, s := range sequences {
-		sum += s.Reverse().Extrapolate()
+func SumValues(polynomials []Polynomial, value func(p Polynomial) *big.Rat) string {
+	sum := new(big.Rat)
+	for _, p := range polynomials {
+		sum.Add(sum, value(p))
 	}
-	return sum
+	return sum.RatString()
 }
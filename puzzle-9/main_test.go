@@ -0,0 +1,80 @@
+package main
+
+import (
+	"aoc/helper"
+	"math/big"
+	"testing"
+)
+
+func parsePolynomials(t *testing.T, lines []string) []Polynomial {
+	t.Helper()
+	sequences := ReadSequences(lines)
+	polynomials := make([]Polynomial, len(sequences))
+	for i, s := range sequences {
+		p, err := s.Polynomial()
+		if err != nil {
+			t.Fatalf("sequence %d: %s", i+1, err.Error())
+		}
+		polynomials[i] = p
+	}
+	return polynomials
+}
+
+func TestExample(t *testing.T) {
+	polynomials := parsePolynomials(t, helper.ReadNonEmptyLines("example-1.txt"))
+	if sum := SumValues(polynomials, func(p Polynomial) *big.Rat { return p.Forward(1) }); sum != "114" {
+		t.Errorf("forward sum is %s instead of 114", sum)
+	}
+	if sum := SumValues(polynomials, func(p Polynomial) *big.Rat { return p.Backward(1) }); sum != "2" {
+		t.Errorf("backward sum is %s instead of 2", sum)
+	}
+}
+
+func TestPolynomial(t *testing.T) {
+	for _, tc := range []struct {
+		sequence Sequence
+		degree   int
+	}{
+		{Sequence{7}, 0},
+		{Sequence{7, 7}, 0},
+		{Sequence{1, 3, 5}, 1},
+		{Sequence{1, 3, 6, 10, 15, 21}, 2},
+	} {
+		p, err := tc.sequence.Polynomial()
+		if err != nil {
+			t.Errorf("%v: %s", tc.sequence, err.Error())
+			continue
+		}
+		if p.Degree() != tc.degree {
+			t.Errorf("%v has degree %d instead of %d", tc.sequence, p.Degree(), tc.degree)
+		}
+	}
+
+	// the highest-order differences would only be confirmed by a single value
+	for _, s := range []Sequence{{}, {1, 2}, {1, 2, 4}, {0, 1, 0, 1, 0}} {
+		if p, err := s.Polynomial(); err == nil {
+			t.Errorf("%v is accepted as polynomial of degree %d", s, p.Degree())
+		}
+	}
+}
+
+func TestAtNegativeIndices(t *testing.T) {
+	// s(n) = n^2 - 3n + 5
+	sequence := make(Sequence, 5)
+	for n := range sequence {
+		sequence[n] = n*n - 3*n + 5
+	}
+	p, err := sequence.Polynomial()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for n := int64(-10); n < 0; n++ {
+		want := big.NewRat(n*n-3*n+5, 1)
+		if got := p.At(big.NewInt(n)); got.Cmp(want) != 0 {
+			t.Errorf("s(%d) is %s instead of %s", n, got.RatString(), want.RatString())
+		}
+	}
+	if got := p.Backward(3); got.Cmp(p.At(big.NewInt(-3))) != 0 {
+		t.Errorf("3 steps backward is %s instead of s(-3)", got.RatString())
+	}
+}